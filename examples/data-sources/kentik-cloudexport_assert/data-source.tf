@@ -0,0 +1,16 @@
+# continuously verify that the exports are healthy (requires Terraform 1.5 or later)
+check "cloud_exports_healthy" {
+  data "kentik-cloudexport_assert" "healthy" {
+    names        = ["test_terraform_aws_export"]
+    status       = "OK"
+    flow_found   = true
+    api_access   = true
+    enabled      = true
+    poll_timeout = "30s"
+  }
+
+  assert {
+    condition     = data.kentik-cloudexport_assert.healthy.passed
+    error_message = jsonencode(data.kentik-cloudexport_assert.healthy.failures)
+  }
+}
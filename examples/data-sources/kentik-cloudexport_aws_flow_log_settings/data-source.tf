@@ -0,0 +1,17 @@
+# VPC flow log settings recommended for Kentik ingestion, delivering the logs to the bucket of the export
+data "kentik-cloudexport_aws_flow_log_settings" "settings" {
+  export_id = "1"
+}
+
+resource "aws_flow_log" "kentik" {
+  vpc_id                   = "vpc-0123456789abcdef0"
+  log_format               = data.kentik-cloudexport_aws_flow_log_settings.settings.log_format
+  traffic_type             = data.kentik-cloudexport_aws_flow_log_settings.settings.traffic_type
+  max_aggregation_interval = data.kentik-cloudexport_aws_flow_log_settings.settings.max_aggregation_interval
+  log_destination_type     = data.kentik-cloudexport_aws_flow_log_settings.settings.log_destination_type
+  log_destination          = data.kentik-cloudexport_aws_flow_log_settings.settings.log_destination
+
+  destination_options {
+    file_format = data.kentik-cloudexport_aws_flow_log_settings.settings.file_format
+  }
+}
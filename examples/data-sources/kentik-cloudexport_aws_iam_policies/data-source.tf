@@ -0,0 +1,17 @@
+# IAM policies required by Kentik to ingest VPC flow logs from the bucket
+data "kentik-cloudexport_aws_iam_policies" "policies" {
+  buckets           = ["terraform-aws-bucket"]
+  region            = "us-east-2"
+  delete_after_read = false
+}
+
+resource "aws_iam_role" "kentik" {
+  name               = "trafficTerraformIngestRole"
+  assume_role_policy = data.kentik-cloudexport_aws_iam_policies.policies.trust_policy_json
+}
+
+resource "aws_iam_role_policy" "kentik" {
+  name   = "kentik-ingest"
+  role   = aws_iam_role.kentik.id
+  policy = data.kentik-cloudexport_aws_iam_policies.policies.permission_policy_json
+}
@@ -0,0 +1,15 @@
+# role assignments required by Kentik service principal to ingest NSG flow logs
+data "kentik-cloudexport_azure_requirements" "requirements" {
+  subscription_id = "784bd5ec-122b-41b7-9719-22f23d5b49c8"
+  resource_group  = "traffic-generator"
+  storage_account = "kentikstorage"
+  principal_id    = "00000000-0000-0000-0000-000000000000" # object ID of Kentik service principal in your tenant
+}
+
+resource "azurerm_role_assignment" "kentik" {
+  for_each = { for a in data.kentik-cloudexport_azure_requirements.requirements.role_assignments : a.key => a }
+
+  scope                = each.value.scope
+  role_definition_name = each.value.role_definition_name
+  principal_id         = each.value.principal_id
+}
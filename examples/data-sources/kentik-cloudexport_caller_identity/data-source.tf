@@ -0,0 +1,13 @@
+# verify that the provider credentials belong to the intended Kentik account
+data "kentik-cloudexport_caller_identity" "current" {
+  lifecycle {
+    postcondition {
+      condition     = self.company_id == "74333" && self.region == "US"
+      error_message = "Kentik credentials belong to company ${self.company_id} in region ${self.region}"
+    }
+  }
+}
+
+output "kentik_user" {
+  value = data.kentik-cloudexport_caller_identity.current.email
+}
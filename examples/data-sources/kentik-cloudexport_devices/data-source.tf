@@ -0,0 +1,24 @@
+# find routers that can provide BGP data to cloud exports
+data "kentik-cloudexport_devices" "bgp_routers" {
+  name_regex  = "^edge-router"
+  bgp_capable = true
+}
+
+resource "kentik-cloudexport_item" "aws_with_bgp" {
+  name           = "aws_with_bgp"
+  type           = "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"
+  enabled        = true
+  plan_id        = "11467"
+  cloud_provider = "aws"
+  aws {
+    bucket            = "terraform-aws-bucket"
+    iam_role_arn      = "arn:aws:iam::003740049406:role/trafficTerraformIngestRole"
+    region            = "us-east-2"
+    delete_after_read = false
+  }
+  bgp {
+    apply_bgp         = true
+    use_bgp_device_id = data.kentik-cloudexport_devices.bgp_routers.devices[0].id
+    device_bgp_type   = "device"
+  }
+}
@@ -0,0 +1,14 @@
+# IAM bindings required by Kentik to consume VPC flow logs from the subscription
+data "kentik-cloudexport_gce_requirements" "requirements" {
+  project      = "project-gce"
+  subscription = "subscription-gce"
+}
+
+resource "google_pubsub_subscription_iam_member" "kentik" {
+  for_each = { for m in data.kentik-cloudexport_gce_requirements.requirements.iam_members : m.role => m }
+
+  project      = each.value.project
+  subscription = each.value.subscription
+  role         = each.value.role
+  member       = each.value.member
+}
@@ -0,0 +1,14 @@
+# summarize health of all cloud exports
+data "kentik-cloudexport_health" "health" {}
+
+output "healthy_share" {
+  value = data.kentik-cloudexport_health.health.healthy_share
+}
+
+output "unhealthy_export_ids" {
+  value = data.kentik-cloudexport_health.health.unhealthy_ids
+}
+
+output "exports_without_flow" {
+  value = data.kentik-cloudexport_health.health.flow_not_found_ids
+}
@@ -5,4 +5,23 @@ data "kentik-cloudexport_item" "export" {
 
 output "export_3" {
   value = data.kentik-cloudexport_item.export
-}
\ No newline at end of file
+}
+
+# fetch single cloud export by the S3 bucket it reads from
+data "kentik-cloudexport_item" "aws_export" {
+  aws_bucket = "terraform-aws-bucket"
+}
+
+output "aws_export" {
+  value = data.kentik-cloudexport_item.aws_export
+}
+
+# fetch single cloud export if it exists - found attribute is false otherwise
+data "kentik-cloudexport_item" "optional_export" {
+  name          = "optional_export"
+  allow_missing = true
+}
+
+output "optional_export_found" {
+  value = data.kentik-cloudexport_item.optional_export.found
+}
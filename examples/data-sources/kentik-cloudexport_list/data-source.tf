@@ -4,4 +4,28 @@ data "kentik-cloudexport_list" "exports" {
 
 output "export_list" {
   value = data.kentik-cloudexport_list.exports
-}
\ No newline at end of file
+}
+
+# fetch enabled AWS cloud exports in us-east-2 region
+data "kentik-cloudexport_list" "aws_exports" {
+  cloud_provider = "aws"
+  enabled        = true
+  aws_region     = "us-east-2"
+}
+
+output "aws_export_list" {
+  value = data.kentik-cloudexport_list.aws_exports
+}
+
+# fetch enabled AWS cloud exports in US regions that are not in OK status
+data "kentik-cloudexport_list" "failing_us_exports" {
+  where = "export.enabled && export.aws[0].region.startsWith('us-') && export.current_status[0].status != 'OK'"
+}
+
+output "failing_us_export_list" {
+  value = data.kentik-cloudexport_list.failing_us_exports
+}
+
+output "aws_export_ids" {
+  value = data.kentik-cloudexport_list.exports.by_cloud_provider[0].aws
+}
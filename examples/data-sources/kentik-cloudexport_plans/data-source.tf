@@ -0,0 +1,17 @@
+# list all active plans
+data "kentik-cloudexport_plans" "active" {
+  active = true
+}
+
+output "plans_with_free_devices" {
+  value = [for p in data.kentik-cloudexport_plans.active.plans : p.name if p.available_devices > 0]
+}
+
+# look up plan_id by plan name
+data "kentik-cloudexport_plans" "flowpak" {
+  name = "Free Flowpak Plan"
+}
+
+output "flowpak_plan_id" {
+  value = data.kentik-cloudexport_plans.flowpak.ids_by_name["Free Flowpak Plan"]
+}
@@ -0,0 +1,2 @@
+# cloud export can be imported by its ID
+terraform import kentik-cloudexport_item.terraform_aws_export 1234
@@ -0,0 +1,16 @@
+package provider
+
+import (
+	"github.com/kentik/community_sdk_golang/kentikapi"
+)
+
+// apiClient groups Kentik API clients used by the provider.
+type apiClient struct {
+	*kentikapi.Client
+	// authEmail is the email of the user the client authenticates as.
+	authEmail string
+	// apiURL is the URL of Kentik API used by the client, including the default one if not configured.
+	apiURL string
+	// portalURL is the URL of Kentik portal of the region of the API, used to link the exports.
+	portalURL string
+}
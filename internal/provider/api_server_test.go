@@ -2,39 +2,69 @@ package provider_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	cloudexportpb "github.com/kentik/api-schema-public/gen/go/kentik/cloud_export/v202101beta1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
-const cloudExportNotFound = -1
+const (
+	cloudExportNotFound = -1
+	// invalidAuthToken is the token rejected by REST API of the server.
+	invalidAuthToken = "invalid-token"
+)
 
+// testAPIServer serves both Kentik APIs used by the provider on a single port:
+// gRPC Cloud Export API and REST API v5 (used for devices, plans and users).
 type testAPIServer struct {
 	cloudexportpb.UnimplementedCloudExportAdminServiceServer
-	server *grpc.Server
+	grpcServer *grpc.Server
+	httpServer *http.Server
 
 	url  string
 	done chan struct{}
 	t    testing.TB
 
-	data []*cloudexportpb.CloudExport
+	// mu guards data, which is accessed by concurrently served requests and by test steps
+	mu      sync.Mutex
+	data    []*cloudexportpb.CloudExport
+	devices []testDevice
+	plans   []testPlan
+	users   []testUser
+	// invalidExportsCount is the number of invalid exports reported by the server, which are not listed
+	invalidExportsCount uint32
+	// getErrorCode is the status code returned by GetCloudExport for all exports, if set
+	getErrorCode codes.Code
+	// beforeList is called before serving ListCloudExport request, if set, e.g. to change export status over time.
+	// It is called with mu locked.
+	beforeList func(data []*cloudexportpb.CloudExport)
 }
 
 func newTestAPIServer(t testing.TB, ces []*cloudexportpb.CloudExport) *testAPIServer {
 	return &testAPIServer{
-		done: make(chan struct{}),
-		t:    t,
-		data: ces,
+		done:    make(chan struct{}),
+		t:       t,
+		data:    ces,
+		devices: makeInitialDevices(),
+		plans:   makeInitialPlans(),
+		users:   makeInitialUsers(),
 	}
 }
 
@@ -43,12 +73,33 @@ func (s *testAPIServer) Start() {
 	require.NoError(s.t, err)
 
 	s.url = l.Addr().String()
-	s.server = grpc.NewServer()
-	cloudexportpb.RegisterCloudExportAdminServiceServer(s.server, s)
+	s.grpcServer = grpc.NewServer()
+	cloudexportpb.RegisterCloudExportAdminServiceServer(s.grpcServer, s)
+
+	restMux := http.NewServeMux()
+	restMux.HandleFunc("/api/v5/devices", s.handleGetDevices)
+	restMux.HandleFunc("/api/v5/plans", s.handleGetPlans)
+	restMux.HandleFunc("/api/v5/users", s.handleGetUsers)
+
+	// gRPC client connects with HTTP/2 without TLS, REST client uses HTTP/1.1
+	s.httpServer = &http.Server{ //nolint: gosec // test server
+		Handler: h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+				s.grpcServer.ServeHTTP(w, r)
+				return
+			}
+			if r.Header.Get("X-CH-Auth-API-Token") == invalidAuthToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				s.writeJSON(w, map[string]interface{}{"error": "Authentication failed"})
+				return
+			}
+			restMux.ServeHTTP(w, r)
+		}), &http2.Server{}),
+	}
 
 	go func() {
-		err = s.server.Serve(l)
-		if !errors.Is(err, grpc.ErrServerStopped) {
+		err = s.httpServer.Serve(l)
+		if !errors.Is(err, http.ErrServerClosed) {
 			assert.NoError(s.t, err)
 		}
 		s.done <- struct{}{}
@@ -57,7 +108,9 @@ func (s *testAPIServer) Start() {
 
 // Stop blocks until the server is stopped.
 func (s *testAPIServer) Stop() {
-	s.server.GracefulStop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(s.t, s.httpServer.Shutdown(ctx))
 	<-s.done
 }
 
@@ -66,20 +119,44 @@ func (s *testAPIServer) URL() string {
 	return fmt.Sprintf("http://%v", s.url)
 }
 
+// withData calls f with the exports stored by the server, e.g. to modify or verify them in test steps.
+func (s *testAPIServer) withData(f func(data []*cloudexportpb.CloudExport)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s.data)
+}
+
 func (s *testAPIServer) ListCloudExport(
 	_ context.Context, _ *cloudexportpb.ListCloudExportRequest,
 ) (*cloudexportpb.ListCloudExportResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.beforeList != nil {
+		s.beforeList(s.data)
+	}
+	exports := make([]*cloudexportpb.CloudExport, 0, len(s.data))
+	for _, ce := range s.data {
+		// the response is serialized after the lock is released, so the stored exports are not returned
+		exports = append(exports, cloneCloudExport(ce))
+	}
 	return &cloudexportpb.ListCloudExportResponse{
-		Exports:             s.data,
-		InvalidExportsCount: 0,
+		Exports:             exports,
+		InvalidExportsCount: s.invalidExportsCount,
 	}, nil
 }
 
 func (s *testAPIServer) GetCloudExport(
 	ctx context.Context, req *cloudexportpb.GetCloudExportRequest,
 ) (*cloudexportpb.GetCloudExportResponse, error) {
+	if s.getErrorCode != codes.OK {
+		return nil, status.Errorf(s.getErrorCode, "failed to get cloud export with ID %q", req.GetId())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if idx := s.findByID(req.GetId()); idx != cloudExportNotFound {
-		return &cloudexportpb.GetCloudExportResponse{Export: s.data[idx]}, nil
+		return &cloudexportpb.GetCloudExportResponse{Export: cloneCloudExport(s.data[idx])}, nil
 	}
 	return nil, status.Errorf(codes.NotFound, "cloud export with ID %q not found", req.GetId())
 }
@@ -87,6 +164,9 @@ func (s *testAPIServer) GetCloudExport(
 func (s *testAPIServer) CreateCloudExport(
 	ctx context.Context, req *cloudexportpb.CreateCloudExportRequest,
 ) (*cloudexportpb.CreateCloudExportResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	newExport := req.GetExport()
 
 	if s.findByName(newExport.Name) != cloudExportNotFound {
@@ -109,18 +189,21 @@ func (s *testAPIServer) CreateCloudExport(
 	s.data = append(s.data, newExport)
 
 	return &cloudexportpb.CreateCloudExportResponse{
-		Export: newExport,
+		Export: cloneCloudExport(newExport),
 	}, nil
 }
 
 func (s *testAPIServer) UpdateCloudExport(
 	ctx context.Context, req *cloudexportpb.UpdateCloudExportRequest,
 ) (*cloudexportpb.UpdateCloudExportResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	exportUpdate := req.GetExport()
 	if i := s.findByID(exportUpdate.GetId()); i != cloudExportNotFound {
 		s.data[i] = exportUpdate
 		return &cloudexportpb.UpdateCloudExportResponse{
-			Export: exportUpdate,
+			Export: cloneCloudExport(exportUpdate),
 		}, nil
 	}
 	return nil, status.Errorf(codes.NotFound, "cloud export of id %q doesn't exists", exportUpdate.Id)
@@ -129,6 +212,9 @@ func (s *testAPIServer) UpdateCloudExport(
 func (s *testAPIServer) DeleteCloudExport(
 	ctx context.Context, req *cloudexportpb.DeleteCloudExportRequest,
 ) (*cloudexportpb.DeleteCloudExportResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if i := s.findByID(req.GetId()); i != cloudExportNotFound {
 		s.data = append(s.data[:i], s.data[i+1:]...)
 		return &cloudexportpb.DeleteCloudExportResponse{}, nil
@@ -136,6 +222,43 @@ func (s *testAPIServer) DeleteCloudExport(
 	return nil, status.Errorf(codes.NotFound, "cloud export of id %q doesn't exists", req.GetId())
 }
 
+func (s *testAPIServer) handleGetDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeJSON(w, map[string]interface{}{"devices": s.devices})
+}
+
+func (s *testAPIServer) handleGetPlans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeJSON(w, map[string]interface{}{"plans": s.plans})
+}
+
+// handleGetUsers serves the users to administrators only, as Kentik API does.
+func (s *testAPIServer) handleGetUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	for _, u := range s.users {
+		if strings.EqualFold(u.UserEmail, r.Header.Get("X-CH-Auth-Email")) && u.Role != "Administrator" {
+			w.WriteHeader(http.StatusForbidden)
+			s.writeJSON(w, map[string]interface{}{"error": "Insufficient privileges"})
+			return
+		}
+	}
+	s.writeJSON(w, map[string]interface{}{"users": s.users})
+}
+
+func (s *testAPIServer) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	assert.NoError(s.t, json.NewEncoder(w).Encode(v))
+}
+
 func (s *testAPIServer) allocateNewID() (string, error) {
 	var id int
 
@@ -152,6 +275,11 @@ func (s *testAPIServer) allocateNewID() (string, error) {
 	return strconv.FormatInt(int64(id)+1, 10), nil
 }
 
+func cloneCloudExport(ce *cloudexportpb.CloudExport) *cloudexportpb.CloudExport {
+	return proto.Clone(ce).(*cloudexportpb.CloudExport) //nolint: forcetypeassert
+}
+
+// findByName returns the index of the export with the name. It must be called with mu locked.
 func (s *testAPIServer) findByName(name string) int {
 	for i, ce := range s.data {
 		if ce.Name == name {
@@ -161,6 +289,7 @@ func (s *testAPIServer) findByName(name string) int {
 	return cloudExportNotFound
 }
 
+// findByID returns the index of the export with the ID. It must be called with mu locked.
 func (s *testAPIServer) findByID(id string) int {
 	for i, ce := range s.data {
 		if ce.Id == id {
@@ -263,3 +392,119 @@ func makeInitialCloudExports() []*cloudexportpb.CloudExport {
 		},
 	}
 }
+
+// testDevice is a device as returned by Kentik REST API v5.
+type testDevice struct {
+	ID               string                 `json:"id"`
+	CompanyID        string                 `json:"company_id"`
+	DeviceName       string                 `json:"device_name"`
+	DeviceType       string                 `json:"device_type"`
+	DeviceSubType    string                 `json:"device_subtype"`
+	DeviceSampleRate string                 `json:"device_sample_rate"`
+	DeviceBGPType    string                 `json:"device_bgp_type"`
+	SendingIPs       []string               `json:"sending_ips"`
+	Plan             map[string]interface{} `json:"plan"`
+	CreatedDate      time.Time              `json:"created_date"`
+	UpdatedDate      time.Time              `json:"updated_date"`
+}
+
+func makeInitialDevices() []testDevice {
+	newDevice := func(id, name, deviceType, subType, bgpType string, sendingIPs []string) testDevice {
+		return testDevice{
+			ID:               id,
+			CompanyID:        "74333",
+			DeviceName:       name,
+			DeviceType:       deviceType,
+			DeviceSubType:    subType,
+			DeviceSampleRate: "1",
+			DeviceBGPType:    bgpType,
+			SendingIPs:       sendingIPs,
+			Plan:             map[string]interface{}{"id": 11467, "name": "Free Flowpak Plan"},
+			CreatedDate:      time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedDate:      time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	return []testDevice{
+		newDevice("1234", "bgp_router", "router", "router", "device", []string{"10.0.0.1"}),
+		newDevice("4444", "bgp_router_2", "router", "router", "device", []string{"10.0.0.2", "10.0.0.3"}),
+		newDevice("5555", "non_bgp_router", "router", "router", "none", []string{"10.0.0.4"}),
+		newDevice("6666", "dns_probe", "host-nprobe-dns-www", "kprobe", "none", nil),
+	}
+}
+
+// testPlan is a plan as returned by Kentik REST API v5.
+type testPlan struct {
+	ID          int                 `json:"id"`
+	CompanyID   int                 `json:"company_id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Active      bool                `json:"active"`
+	MaxDevices  int                 `json:"max_devices"`
+	MaxFPS      int                 `json:"max_fps"`
+	BGPEnabled  bool                `json:"bgp_enabled"`
+	CreatedDate time.Time           `json:"cdate"`
+	Devices     []map[string]string `json:"devices"`
+}
+
+func makeInitialPlans() []testPlan {
+	newPlan := func(id int, name string, active bool, maxDevices int, deviceIDs ...string) testPlan {
+		devices := []map[string]string{}
+		for _, deviceID := range deviceIDs {
+			devices = append(devices, map[string]string{
+				"id":          deviceID,
+				"device_name": "device_" + deviceID,
+				"device_type": "router",
+			})
+		}
+		return testPlan{
+			ID:          id,
+			CompanyID:   74333,
+			Name:        name,
+			Description: name + " description",
+			Active:      active,
+			MaxDevices:  maxDevices,
+			MaxFPS:      1000,
+			BGPEnabled:  true,
+			CreatedDate: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			Devices:     devices,
+		}
+	}
+	return []testPlan{
+		newPlan(21600, "Cloud Plan", true, 100),
+		newPlan(11467, "Free Flowpak Plan", true, 5, "1234", "4444", "5555", "6666"),
+		newPlan(9948, "Legacy Plan", false, 10, "7777"),
+	}
+}
+
+// testUser is a user as returned by Kentik REST API v5.
+type testUser struct {
+	ID           string    `json:"id"`
+	CompanyID    string    `json:"company_id"`
+	Username     string    `json:"username"`
+	UserFullName string    `json:"user_full_name"`
+	UserEmail    string    `json:"user_email"`
+	Role         string    `json:"role"`
+	EmailService bool      `json:"email_service"`
+	EmailProduct bool      `json:"email_product"`
+	CreatedDate  time.Time `json:"created_date"`
+	UpdatedDate  time.Time `json:"updated_date"`
+}
+
+func makeInitialUsers() []testUser {
+	newUser := func(id, email, fullName, role string) testUser {
+		return testUser{
+			ID:           id,
+			CompanyID:    "74333",
+			Username:     email,
+			UserFullName: fullName,
+			UserEmail:    email,
+			Role:         role,
+			CreatedDate:  time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedDate:  time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	return []testUser{
+		newUser("145999", "admin@example.com", "Admin", "Administrator"),
+		newUser("146000", "Joe.Doe@example.com", "Joe Doe", "Member"),
+	}
+}
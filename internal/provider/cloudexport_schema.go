@@ -2,6 +2,7 @@ package provider
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -16,14 +17,32 @@ const (
 	azureKey = "azure"
 	gceKey   = "gce"
 	ibmKey   = "ibm"
+
+	awsBucketKey          = "bucket"
+	awsMultipleBucketsKey = "multiple_buckets"
+
+	namePrefixKey = "name_prefix"
+	portalURLKey  = "portal_url"
+
+	awsAccountIDKey          = "aws_account_id"
+	awsRoleNameKey           = "aws_role_name"
+	awsBucketARNKey          = "aws_bucket_arn"
+	azureStorageAccountIDKey = "azure_storage_account_id"
+	gceSubscriptionPathKey   = "gce_subscription_path"
+
+	bgpDeviceIDKey      = "bgp_device_id"
+	useBGPDeviceIDKey   = "use_bgp_device_id"
+	useBGPDeviceNameKey = "use_bgp_device_name"
+	bgpDeviceIDPath     = "bgp.0." + useBGPDeviceIDKey
+	bgpDeviceNamePath   = "bgp.0." + useBGPDeviceNameKey
 )
 
 func makeCloudExportSchema(mode schemaMode) map[string]*schema.Schema {
-	return map[string]*schema.Schema{
+	s := map[string]*schema.Schema{
 		"id": {
 			Type:        schema.TypeString,
-			Computed:    mode == create || mode == readList, // provided by server on creating/listing items
-			Required:    mode == readSingle,                 // provided by user in order to read single item
+			Computed:    true,               // provided by server on creating/listing items or looking up single item
+			Optional:    mode == readSingle, // optionally provided by user in order to read single item
 			Description: "The internal cloud export identifier. This is Read-only and assigned by Kentik",
 		},
 		"type": {
@@ -48,10 +67,11 @@ func makeCloudExportSchema(mode schemaMode) map[string]*schema.Schema {
 			Description: "Whether this task is enabled and intended to run, or disabled",
 		},
 		"name": {
-			Type:        schema.TypeString,
-			Computed:    mode == readSingle || mode == readList, // provided by server on read
-			Required:    mode == create,                         // provided by user on create
-			Description: "A short name for this export",
+			Type:         schema.TypeString,
+			Computed:     true,                                 // provided by server on read or generated on create
+			Optional:     mode == create || mode == readSingle, // optionally provided by user on create or lookup
+			Description:  "A short name for this export. Must be unique",
+			ExactlyOneOf: skipOnReadOneOf(mode, []string{"name", namePrefixKey}),
 		},
 		"description": {
 			Type:        schema.TypeString,
@@ -84,7 +104,63 @@ func makeCloudExportSchema(mode schemaMode) map[string]*schema.Schema {
 		ibmKey:           makeIBMSchema(mode),
 		"bgp":            makeBGPSchema(mode),
 		"current_status": makeCurrentStatusSchema(),
+		portalURLKey: {
+			Type:     schema.TypeString,
+			Computed: true, // derived from API URL and ID by provider
+			Description: "URL of the export page in Kentik portal. " +
+				"The portal region is derived from the apiurl of the provider. " +
+				"Empty for custom apiurl whose host does not begin with api., as its portal is not known",
+		},
+		// the identifiers are top-level attributes, so that they can be marked as unknown on plan
+		awsAccountIDKey: {
+			Type:        schema.TypeString,
+			Computed:    true, // derived from aws.iam_role_arn by provider
+			Description: "AWS account ID of the IAM role, derived from aws.iam_role_arn",
+		},
+		awsRoleNameKey: {
+			Type:        schema.TypeString,
+			Computed:    true, // derived from aws.iam_role_arn by provider
+			Description: "Name of the IAM role, derived from aws.iam_role_arn",
+		},
+		awsBucketARNKey: {
+			Type:        schema.TypeString,
+			Computed:    true, // derived from aws.bucket and aws.region by provider
+			Description: "ARN of the S3 bucket, derived from aws.bucket and aws.region",
+		},
+		azureStorageAccountIDKey: {
+			Type:     schema.TypeString,
+			Computed: true, // derived from azure properties by provider
+			Description: "Azure resource ID of the storage account, " +
+				"derived from azure.subscription_id, azure.resource_group and azure.storage_account",
+		},
+		gceSubscriptionPathKey: {
+			Type:     schema.TypeString,
+			Computed: true, // derived from gce properties by provider
+			Description: "Fully qualified path of the subscription, i.e. projects/<project>/subscriptions/<name>, " +
+				"derived from gce.project and gce.subscription",
+		},
 	}
+	if mode == create {
+		// nested attributes cannot be marked as unknown on plan, so the resolved ID is a top-level attribute
+		s[bgpDeviceIDKey] = &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+			Description: "ID of the device providing BGP data to the export, i.e. bgp.use_bgp_device_id " +
+				"or the ID resolved from bgp.use_bgp_device_name on plan",
+		}
+		// the prefix is not stored by the server, so it is only available in the resource
+		s[namePrefixKey] = &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: true,
+			Description: "Creates a unique name beginning with the specified prefix. " +
+				"The generated name is available in name attribute. " +
+				"Exactly one of name and name_prefix must be provided. " +
+				"On import, it is derived from the name if the name ends with a generated suffix",
+			ExactlyOneOf: []string{"name", namePrefixKey},
+		}
+	}
+	return s
 }
 
 func makeAWSSchema(mode schemaMode) *schema.Schema {
@@ -97,7 +173,7 @@ func makeAWSSchema(mode schemaMode) *schema.Schema {
 		ExactlyOneOf: skipOnReadOneOf(mode, []string{awsKey, azureKey, gceKey, ibmKey}),
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
-				"bucket": {
+				awsBucketKey: {
 					Type:        schema.TypeString,
 					Computed:    mode == readSingle || mode == readList, // provided by server on read
 					Required:    mode == create,                         // provided by user on create
@@ -121,10 +197,12 @@ func makeAWSSchema(mode schemaMode) *schema.Schema {
 					Required:    mode == create,                         // provided by user on create
 					Description: "If true, attempt to delete vpc flow log chunks from S3 after they've been read",
 				},
-				"multiple_buckets": {
+				awsMultipleBucketsKey: {
 					Type:     schema.TypeBool,
 					Computed: mode == readSingle || mode == readList, // provided by server on read
 					Required: mode == create,                         // provided by user on create
+					Description: "Whether the export reads vpc flow logs from multiple buckets. " +
+						"The flag is passed to Cloud Export API as is",
 				},
 			},
 		},
@@ -172,33 +250,48 @@ func makeAzureSchema(mode schemaMode) *schema.Schema {
 }
 
 func makeBGPSchema(mode schemaMode) *schema.Schema {
+	s := map[string]*schema.Schema{
+		"apply_bgp": {
+			Type:        schema.TypeBool,
+			Computed:    mode == readSingle || mode == readList, // provided by server on read
+			Required:    mode == create,                         // provided by user on create
+			Description: "If true, apply BGP data discovered via another device to the flow from this export",
+		},
+		useBGPDeviceIDKey: {
+			Type:     schema.TypeString,
+			Computed: mode == readSingle || mode == readList, // provided by server on read
+			Optional: mode == create,                         // optionally provided by user on create
+			Description: "Which other device to get BGP data from. The device must exist and be a router " +
+				"with BGP enabled",
+			ExactlyOneOf: skipOnReadOneOf(mode, []string{bgpDeviceIDPath, bgpDeviceNamePath}),
+		},
+		"device_bgp_type": {
+			Type:        schema.TypeString,
+			Computed:    mode == readSingle || mode == readList, // provided by server on read
+			Required:    mode == create,                         // provided by user on create
+			Description: "device, other_device, none",
+		},
+	}
+	if mode == create {
+		// the name is not stored by the server, so it is only available in the resource
+		s[useBGPDeviceNameKey] = &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Description: "Name of the other device to get BGP data from. " +
+				"It is resolved to use_bgp_device_id by the provider",
+			ExactlyOneOf: []string{bgpDeviceIDPath, bgpDeviceNamePath},
+		}
+	}
+
 	return &schema.Schema{
 		// nested object
 		Type:        schema.TypeList,
 		Computed:    mode == readSingle || mode == readList, // provided by server on read
 		Optional:    mode == create,                         // optionally provided by user on create
 		Description: "Optional BGP related settings",
+		MaxItems:    skipOnReadMaxItems(mode, 1), // required by ExactlyOneOf of nested attributes
 		Elem: &schema.Resource{
-			Schema: map[string]*schema.Schema{
-				"apply_bgp": {
-					Type:        schema.TypeBool,
-					Computed:    mode == readSingle || mode == readList, // provided by server on read
-					Required:    mode == create,                         // provided by user on create
-					Description: "If true, apply BGP data discovered via another device to the flow from this export",
-				},
-				"use_bgp_device_id": {
-					Type:        schema.TypeString,
-					Computed:    mode == readSingle || mode == readList, // provided by server on read
-					Required:    mode == create,                         // provided by user on create
-					Description: "Which other device to get BGP data from",
-				},
-				"device_bgp_type": {
-					Type:        schema.TypeString,
-					Computed:    mode == readSingle || mode == readList, // provided by server on read
-					Required:    mode == create,                         // provided by user on create
-					Description: "device, other_device, none",
-				},
-			},
+			Schema: s,
 		},
 	}
 }
@@ -298,14 +391,17 @@ func cloudExportToMap(e *models.CloudExport) map[string]interface{} {
 	o["description"] = e.Description
 	o["plan_id"] = e.PlanID
 	o["cloud_provider"] = e.CloudProvider
+	for k, v := range makeCloudIdentifiers(e.Properties) {
+		o[k] = v
+	}
 
 	if e.GetAWSProperties() != nil {
 		aws := make(map[string]interface{})
-		aws["bucket"] = e.GetAWSProperties().Bucket
+		aws[awsBucketKey] = e.GetAWSProperties().Bucket
 		aws["iam_role_arn"] = e.GetAWSProperties().IAMRoleARN
 		aws["region"] = e.GetAWSProperties().Region
 		aws["delete_after_read"] = e.GetAWSProperties().DeleteAfterRead
-		aws["multiple_buckets"] = e.GetAWSProperties().MultipleBuckets
+		aws[awsMultipleBucketsKey] = e.GetAWSProperties().MultipleBuckets
 		o["aws"] = []interface{}{aws}
 	}
 
@@ -322,7 +418,7 @@ func cloudExportToMap(e *models.CloudExport) map[string]interface{} {
 	if e.BGP != nil {
 		bgp := make(map[string]interface{})
 		bgp["apply_bgp"] = e.BGP.ApplyBGP
-		bgp["use_bgp_device_id"] = e.BGP.UseBGPDeviceID
+		bgp[useBGPDeviceIDKey] = e.BGP.UseBGPDeviceID
 		bgp["device_bgp_type"] = e.BGP.DeviceBGPType
 		o["bgp"] = []interface{}{bgp}
 	}
@@ -353,6 +449,42 @@ func cloudExportToMap(e *models.CloudExport) map[string]interface{} {
 	return o
 }
 
+// makeCloudIdentifiers returns the identifiers of cloud resources derived from the export properties.
+// The identifiers not applicable to the cloud provider of the export are empty.
+func makeCloudIdentifiers(properties models.CloudExportProperties) map[string]interface{} {
+	ids := map[string]interface{}{
+		awsAccountIDKey:          "",
+		awsRoleNameKey:           "",
+		awsBucketARNKey:          "",
+		azureStorageAccountIDKey: "",
+		gceSubscriptionPathKey:   "",
+	}
+	switch p := properties.(type) {
+	case *models.AWSProperties:
+		ids[awsAccountIDKey], ids[awsRoleNameKey] = parseIAMRoleARN(p.IAMRoleARN)
+		if p.Bucket != "" {
+			ids[awsBucketARNKey] = makeS3BucketARN(p.Bucket, p.Region)
+		}
+	case *models.AzureProperties:
+		ids[azureStorageAccountIDKey] = makeAzureStorageAccountID(p.SubscriptionID, p.ResourceGroup, p.StorageAccount)
+	case *models.GCEProperties:
+		// subscription path of other project is not valid, so the path is left empty
+		ids[gceSubscriptionPathKey], _ = makeGCESubscriptionPath(p.Project, p.Subscription)
+	}
+	return ids
+}
+
+// parseIAMRoleARN returns the account ID and the role name of IAM role ARN,
+// e.g. arn:aws:iam::003740049406:role/path/name. Empty values are returned for invalid ARNs.
+func parseIAMRoleARN(arn string) (accountID string, roleName string) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" || !strings.HasPrefix(parts[5], "role/") {
+		return "", ""
+	}
+	resource := strings.Split(parts[5], "/")
+	return parts[4], resource[len(resource)-1]
+}
+
 // resourceDataToCloudExport is used for API create/update operations to fill cloudexport item from terraform resource.
 func resourceDataToCloudExport(d *schema.ResourceData) (*models.CloudExport, error) {
 	// Note: only set the user-writable attributes and ID. Read-only attributes that are only generated on server side:
@@ -371,7 +503,7 @@ func resourceDataToCloudExport(d *schema.ResourceData) (*models.CloudExport, err
 		export.Enabled = &v
 	}
 
-	// required
+	// provided by user or generated on create
 	if v, ok := d.Get("name").(string); ok {
 		export.Name = v
 	}
@@ -407,7 +539,12 @@ func resourceDataToCloudExport(d *schema.ResourceData) (*models.CloudExport, err
 	return &export, nil
 }
 
-func resourceDataToCEProperties(cloudProvider string, d *schema.ResourceData) (models.CloudExportProperties, error) {
+// resourceGetter provides the values of attributes, e.g. schema.ResourceData or schema.ResourceDiff.
+type resourceGetter interface {
+	GetOk(key string) (interface{}, bool)
+}
+
+func resourceDataToCEProperties(cloudProvider string, d resourceGetter) (models.CloudExportProperties, error) {
 	// validation: for any given cloud_provider, there should also be an object of the same name,
 	// containing configuration details, e.g. for cloud_provider="ibm", ibm{...} object should be defined
 	providerObj, ok := d.GetOk(cloudProvider)
@@ -420,14 +557,14 @@ func resourceDataToCEProperties(cloudProvider string, d *schema.ResourceData) (m
 	case "aws":
 		{
 			aws := models.AWSProperties{
-				Bucket:     providerMap["bucket"].(string),
+				Bucket:     providerMap[awsBucketKey].(string),
 				IAMRoleARN: providerMap["iam_role_arn"].(string),
 				Region:     providerMap["region"].(string),
 			}
 			if v, ok := providerMap["delete_after_read"].(bool); ok {
 				aws.DeleteAfterRead = &v
 			}
-			if v, ok := providerMap["multiple_buckets"].(bool); ok {
+			if v, ok := providerMap[awsMultipleBucketsKey].(bool); ok {
 				aws.MultipleBuckets = &v
 			}
 			return &aws, nil
@@ -474,7 +611,7 @@ func resourceDataToBGPProperties(d *schema.ResourceData) (*models.BGPProperties,
 		return nil, nil
 	}
 	bgp := models.BGPProperties{
-		UseBGPDeviceID: m["use_bgp_device_id"].(string),
+		UseBGPDeviceID: m[useBGPDeviceIDKey].(string),
 		DeviceBGPType:  m["device_bgp_type"].(string),
 	}
 	if v, ok := m["apply_bgp"].(bool); ok {
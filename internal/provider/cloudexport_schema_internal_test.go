@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/AlekSi/pointer"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCloudExportSerializerAlwaysSetsTheEnabledField(t *testing.T) {
@@ -54,6 +58,95 @@ func TestCloudExportSerializerAlwaysSetsTheEnabledField(t *testing.T) {
 	}
 }
 
+func TestCloudExportAWSBucketRoundTrip(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		properties models.AWSProperties
+	}{
+		{
+			name: "single bucket",
+			properties: models.AWSProperties{
+				Bucket:          "bucket-1",
+				MultipleBuckets: pointer.ToBool(false),
+			},
+		},
+		{
+			name: "multiple buckets flag",
+			properties: models.AWSProperties{
+				Bucket:          "bucket-1",
+				MultipleBuckets: pointer.ToBool(true),
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// given
+			tt.properties.IAMRoleARN = "arn:aws:iam::003740049406:role/trafficTerraformIngestRole"
+			tt.properties.Region = "us-east-2"
+			tt.properties.DeleteAfterRead = pointer.ToBool(false)
+			input := &models.CloudExport{
+				Name:          "test_aws_export",
+				PlanID:        "11467",
+				CloudProvider: models.CloudProviderAWS,
+				Properties:    &tt.properties,
+			}
+
+			// when
+			m := cloudExportToMap(input)
+			aws := m[awsKey].([]interface{})[0].(map[string]interface{}) //nolint: forcetypeassert
+			assert.Equal(t, tt.properties.Bucket, aws[awsBucketKey])
+			assert.Equal(t, tt.properties.MultipleBuckets, aws[awsMultipleBucketsKey])
+
+			d := resourceCloudExport().Data(nil)
+			for k, v := range m {
+				require.NoError(t, d.Set(k, v))
+			}
+			output, err := resourceDataToCloudExport(d)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, &tt.properties, output.GetAWSProperties())
+		})
+	}
+}
+
+func TestParseIAMRoleARN(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		arn               string
+		expectedAccountID string
+		expectedRoleName  string
+	}{
+		{
+			arn:               "arn:aws:iam::003740049406:role/trafficTerraformIngestRole",
+			expectedAccountID: "003740049406",
+			expectedRoleName:  "trafficTerraformIngestRole",
+		}, {
+			arn:               "arn:aws-us-gov:iam::003740049406:role/kentik/ingest/trafficTerraformIngestRole",
+			expectedAccountID: "003740049406",
+			expectedRoleName:  "trafficTerraformIngestRole",
+		}, {
+			arn: "arn:aws:iam::003740049406:user/joe",
+		}, {
+			arn: "arn:aws:s3:::terraform-aws-bucket",
+		}, {
+			arn: "not an ARN",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.arn, func(t *testing.T) {
+			t.Parallel()
+			accountID, roleName := parseIAMRoleARN(tt.arn)
+			assert.Equal(t, tt.expectedAccountID, accountID)
+			assert.Equal(t, tt.expectedRoleName, roleName)
+		})
+	}
+}
+
 func makeDummyResourceData(t *testing.T) *schema.ResourceData {
 	type ProviderDefinition = map[string]interface{} // groups provider's attributes
 	const provider = "ibm"
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Where expressions select cloud exports using Common Expression Language (CEL).
+// The export, as represented in Terraform state, is available as "export" variable,
+// e.g. export.cloud_provider == 'aws' && export.aws[0].region.startsWith('us-').
+// Note: the attributes are not declared as separate variables, because "type" is a reserved identifier in CEL.
+
+const whereExportVariable = "export"
+
+func validateWhereExpression(v interface{}, path cty.Path) diag.Diagnostics {
+	if _, err := compileWhereExpression(v.(string)); err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid where expression",
+			Detail:        err.Error(),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+// compileWhereExpression parses and type-checks the expression. The error contains the position of the issue.
+func compileWhereExpression(expression string) (cel.Program, error) {
+	env, err := makeWhereEnv()
+	if err != nil {
+		return nil, fmt.Errorf("create expression environment: %v", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if t := ast.OutputType().String(); t != cel.BoolType.String() && t != cel.DynType.String() {
+		return nil, fmt.Errorf("the expression must evaluate to bool, got %v", t)
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("create expression program: %v", err)
+	}
+	return program, nil
+}
+
+// makeWhereEnv declares the export as a variable of dynamic type, as the attributes depend on cloud provider.
+func makeWhereEnv() (*cel.Env, error) {
+	return cel.NewEnv(cel.Variable(whereExportVariable, cel.DynType))
+}
+
+// evaluateWhereExpression reports whether the export, represented as an item of the list, matches the expression.
+// The export does not match if the expression accesses a nested object absent for the export,
+// e.g. aws attribute of a non-AWS export. Other evaluation errors, e.g. of a misspelt attribute, are returned.
+func evaluateWhereExpression(program cel.Program, item map[string]interface{}) (bool, error) {
+	vars, err := cloudExportToWhereVariables(item)
+	if err != nil {
+		return false, err
+	}
+
+	out, _, err := program.Eval(vars)
+	if err != nil {
+		if isAbsentNestedObjectError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	match, ok := out.Value().(bool)
+	return ok && match, nil
+}
+
+// isAbsentNestedObjectError reports whether the evaluation failed on access to a nested object,
+// which is absent for the export. The expression language reports it as a missing key of the export.
+func isAbsentNestedObjectError(err error) bool {
+	key := strings.TrimPrefix(err.Error(), "no such key: ")
+	if key == err.Error() {
+		return false
+	}
+	s, ok := makeCloudExportSchema(readList)[key]
+	return ok && s.Type == schema.TypeList
+}
+
+// cloudExportToWhereVariables converts the export to plain values supported by the expression language.
+func cloudExportToWhereVariables(item map[string]interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("marshal cloud export: %v", err)
+	}
+
+	var export map[string]interface{}
+	if err = json.Unmarshal(b, &export); err != nil {
+		return nil, fmt.Errorf("unmarshal cloud export: %v", err)
+	}
+	return map[string]interface{}{whereExportVariable: export}, nil
+}
@@ -1,11 +1,17 @@
 package provider_test
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/kentik/terraform-provider-kentik-cloudexport/internal/provider"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -22,3 +28,32 @@ func providerFactories() map[string]func() (*schema.Provider, error) {
 		},
 	}
 }
+
+// readDataSource reads the data source with given configuration from the server like Terraform does,
+// and returns the diagnostics, which test steps expose only if they contain an error.
+func readDataSource(
+	t *testing.T, serverURL string, dataSource string, config map[string]interface{},
+) (*terraform.InstanceState, diag.Diagnostics) {
+	t.Helper()
+	ctx := context.Background()
+
+	p := provider.New()
+	diags := p.Configure(ctx, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"apiurl": serverURL,
+		"email":  "joe.doe@example.com",
+		"token":  "dummy-token",
+	}))
+	require.False(t, diags.HasError(), diags)
+
+	r := p.DataSourcesMap[dataSource]
+	diff, err := r.Diff(ctx, nil, terraform.NewResourceConfigRaw(config), p.Meta())
+	require.NoError(t, err)
+
+	// the attributes absent in the configuration are null
+	configJSON, err := json.Marshal(config)
+	require.NoError(t, err)
+	diff.RawConfig, err = ctyjson.Unmarshal(configJSON, r.CoreConfigSchema().ImpliedType())
+	require.NoError(t, err)
+
+	return r.ReadDataApply(ctx, diff, p.Meta())
+}
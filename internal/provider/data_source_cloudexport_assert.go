@@ -0,0 +1,300 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+)
+
+const (
+	pollTimeoutKey      = "poll_timeout"
+	pollIntervalKey     = "poll_interval"
+	defaultPollInterval = "5s"
+	// maxPollTimeout limits the time the data source may block Terraform operations.
+	maxPollTimeout = 5 * time.Minute
+)
+
+func dataSourceCloudExportAssert() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source asserting that cloud exports meet the expectations, intended for Terraform check blocks. " +
+			"The exports are selected by ids and names. Only the configured expectations are verified. " +
+			"The data source does not fail if the expectations are not met - passed and failures attributes " +
+			"report the result",
+		ReadContext: dataSourceCloudExportAssertRead,
+		Schema: map[string]*schema.Schema{
+			"ids": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Description:  "IDs of the exports to verify",
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				AtLeastOneOf: []string{"ids", "names"},
+			},
+			"names": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Description:  "Names of the exports to verify",
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				AtLeastOneOf: []string{"ids", "names"},
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Expected current_status.status of the exports, e.g. OK",
+			},
+			"flow_found": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Expected current_status.flow_found of the exports",
+			},
+			"api_access": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Expected current_status.api_access of the exports",
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Expected enabled state of the exports",
+			},
+			pollTimeoutKey: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Time to wait for the expectations to be met, e.g. when the export status is pending " +
+					"after recent changes. The exports are verified once if not set. Maximum: 5m. " +
+					"Expected Go time duration format, e.g. 30s (see: <https://pkg.go.dev/time#ParseDuration>)",
+				ValidateDiagFunc: validateDuration(maxPollTimeout),
+			},
+			pollIntervalKey: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  defaultPollInterval,
+				Description: "Delay between verifications within poll_timeout. Default: 5s. " +
+					"Expected Go time duration format",
+				ValidateDiagFunc: validateDuration(maxPollTimeout),
+			},
+			"passed": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether all the exports exist and meet the expectations",
+			},
+			"failures": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Exports not meeting the expectations, in order of ids and then names",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the export",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the export",
+						},
+						"reasons": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Descriptions of unmet expectations",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// validateDuration validates that the value is a positive duration not longer than maxDuration.
+func validateDuration(maxDuration time.Duration) schema.SchemaValidateDiagFunc {
+	return func(v interface{}, path cty.Path) diag.Diagnostics {
+		d, err := time.ParseDuration(v.(string))
+		if err == nil && (d <= 0 || d > maxDuration) {
+			err = fmt.Errorf("expected duration greater than 0 and not longer than %v, got %v", maxDuration, d)
+		}
+		if err != nil {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid duration",
+				Detail:        err.Error(),
+				AttributePath: path,
+			}}
+		}
+		return nil
+	}
+}
+
+// cloudExportExpectations holds the expected values of export attributes. Nil values are not verified.
+type cloudExportExpectations struct {
+	status    string
+	flowFound *bool
+	apiAccess *bool
+	enabled   *bool
+}
+
+func newCloudExportExpectations(d *schema.ResourceData) *cloudExportExpectations {
+	return &cloudExportExpectations{
+		status:    d.Get("status").(string),
+		flowFound: getOptionalBool(d, "flow_found"),
+		apiAccess: getOptionalBool(d, "api_access"),
+		enabled:   getOptionalBool(d, "enabled"),
+	}
+}
+
+// unmetBy returns the descriptions of the expectations the export does not meet.
+func (x *cloudExportExpectations) unmetBy(e *models.CloudExport) []interface{} {
+	var status models.CloudExportStatus
+	if e.CurrentStatus != nil {
+		status = *e.CurrentStatus
+	}
+
+	reasons := []interface{}{}
+	if x.status != "" && status.Status != x.status {
+		reasons = append(reasons, fmt.Sprintf("status is %q, expected %q", status.Status, x.status))
+	}
+	checkBool := func(attribute string, actual *bool, expected *bool) {
+		if expected != nil && (actual == nil || *actual != *expected) {
+			reasons = append(reasons, fmt.Sprintf("%v is %v, expected %v", attribute, formatOptionalBool(actual), *expected))
+		}
+	}
+	checkBool("flow_found", status.FlowFound, x.flowFound)
+	checkBool("api_access", status.APIAccess, x.apiAccess)
+	checkBool("enabled", e.Enabled, x.enabled)
+	return reasons
+}
+
+func formatOptionalBool(b *bool) string {
+	if b == nil {
+		return "not reported"
+	}
+	return fmt.Sprint(*b)
+}
+
+func dataSourceCloudExportAssertRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	timeout, interval, err := getPollConfig(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	deadline := time.Now().Add(timeout)
+	expectations := newCloudExportExpectations(d)
+
+	var failures []interface{}
+	for {
+		failures, err = assertCloudExports(ctx, d, m, expectations)
+		if err != nil {
+			return detailedDiagError("Failed to verify cloud exports", err)
+		}
+		if len(failures) == 0 || time.Now().Add(interval).After(deadline) {
+			break
+		}
+
+		tflog.Debug(ctx, "Cloud exports do not meet the expectations, polling", map[string]interface{}{
+			"failures": failures,
+		})
+		select {
+		case <-ctx.Done():
+			return diag.FromErr(ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+
+	if err = d.Set("passed", len(failures) == 0); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("failures", failures); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = setContentHashID(d, failures); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func getPollConfig(d *schema.ResourceData) (timeout time.Duration, interval time.Duration, err error) {
+	if v, ok := d.GetOk(pollTimeoutKey); ok {
+		timeout, err = time.ParseDuration(v.(string))
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse %v duration: %v", pollTimeoutKey, err)
+		}
+	}
+	interval, err = time.ParseDuration(d.Get(pollIntervalKey).(string))
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse %v duration: %v", pollIntervalKey, err)
+	}
+	return timeout, interval, nil
+}
+
+// assertCloudExports returns the failures of the exports selected by ids and names.
+func assertCloudExports(
+	ctx context.Context, d *schema.ResourceData, m interface{}, x *cloudExportExpectations,
+) ([]interface{}, error) {
+	tflog.Debug(ctx, "List cloud export Kentik API request")
+	listResp, err := m.(*apiClient).CloudExports.GetAll(ctx)
+	tflog.Debug(ctx, "List cloud export Kentik API response", map[string]interface{}{"response": listResp})
+	if err != nil {
+		return nil, fmt.Errorf("get cloud export list: %v", err)
+	}
+
+	var exports []models.CloudExport
+	if listResp != nil {
+		exports = listResp.CloudExports
+	}
+
+	failures := []interface{}{}
+	addFailure := func(id string, name string, reasons ...interface{}) {
+		failures = append(failures, map[string]interface{}{"id": id, "name": name, "reasons": reasons})
+	}
+	verify := func(e *models.CloudExport) {
+		if reasons := x.unmetBy(e); len(reasons) > 0 {
+			addFailure(e.ID, e.Name, reasons...)
+		}
+	}
+
+	for _, id := range sortedStrings(d.Get("ids").(*schema.Set)) {
+		if e := findCloudExport(exports, func(e *models.CloudExport) bool { return e.ID == id }); len(e) > 0 {
+			verify(e[0])
+		} else {
+			addFailure(id, "", "cloud export not found")
+		}
+	}
+	for _, name := range sortedStrings(d.Get("names").(*schema.Set)) {
+		found := findCloudExport(exports, func(e *models.CloudExport) bool { return e.Name == name })
+		if len(found) == 0 {
+			addFailure("", name, "cloud export not found")
+		}
+		// exports with the same name are all verified
+		for _, e := range found {
+			verify(e)
+		}
+	}
+	return failures, nil
+}
+
+func findCloudExport(exports []models.CloudExport, matches func(*models.CloudExport) bool) []*models.CloudExport {
+	var found []*models.CloudExport
+	for i := range exports {
+		if matches(&exports[i]) {
+			found = append(found, &exports[i])
+		}
+	}
+	return found
+}
+
+func sortedStrings(s *schema.Set) []string {
+	values := make([]string, 0, s.Len())
+	for _, v := range s.List() {
+		values = append(values, v.(string))
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,165 @@
+package provider_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	cloudexportpb "github.com/kentik/api-schema-public/gen/go/kentik/cloud_export/v202101beta1"
+)
+
+const assertDS = "data.kentik-cloudexport_assert.assert"
+
+func TestDataSourceCloudExportAssert(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceAssert(server.URL(), `
+					ids = ["1"]
+					names = ["test_terraform_azure_export"]
+					status = "OK"
+					enabled = true
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(assertDS, "passed", "true"),
+					resource.TestCheckResourceAttr(assertDS, "failures.#", "0"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAssert(server.URL(), `
+					ids = ["1", "2", "100"]
+					names = ["test_terraform_ibm_export"]
+					status = "OK"
+					flow_found = true
+					enabled = true
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(assertDS, "passed", "false"),
+					resource.TestCheckResourceAttr(assertDS, "failures.#", "3"),
+					resource.TestCheckResourceAttr(assertDS, "failures.0.id", "100"),
+					resource.TestCheckResourceAttr(assertDS, "failures.0.name", ""),
+					resource.TestCheckResourceAttr(assertDS, "failures.0.reasons.#", "1"),
+					resource.TestCheckResourceAttr(assertDS, "failures.0.reasons.0", "cloud export not found"),
+					resource.TestCheckResourceAttr(assertDS, "failures.1.id", "2"),
+					resource.TestCheckResourceAttr(assertDS, "failures.1.name", "test_terraform_gce_export"),
+					resource.TestCheckResourceAttr(assertDS, "failures.1.reasons.#", "2"),
+					resource.TestCheckResourceAttr(assertDS, "failures.1.reasons.0", `status is "NOK", expected "OK"`),
+					resource.TestCheckResourceAttr(
+						assertDS, "failures.1.reasons.1", "flow_found is not reported, expected true",
+					),
+					resource.TestCheckResourceAttr(assertDS, "failures.2.id", "3"),
+					resource.TestCheckResourceAttr(assertDS, "failures.2.name", "test_terraform_ibm_export"),
+					resource.TestCheckResourceAttr(assertDS, "failures.2.reasons.#", "2"),
+					resource.TestCheckResourceAttr(
+						assertDS, "failures.2.reasons.0", "flow_found is not reported, expected true",
+					),
+					resource.TestCheckResourceAttr(assertDS, "failures.2.reasons.1", "enabled is false, expected true"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAssert(server.URL(), `
+					names = ["test_terraform_ibm_export"]
+					enabled = false
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(assertDS, "passed", "true"),
+					resource.TestCheckResourceAttr(assertDS, "failures.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportAssertPolling(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	listCount := 0
+	server.beforeList = func(data []*cloudexportpb.CloudExport) {
+		// GCE export becomes healthy after the first verification
+		listCount++
+		if listCount > 1 {
+			for _, ce := range data {
+				if ce.Id == "2" {
+					ce.CurrentStatus.Status = "OK"
+				}
+			}
+		}
+	}
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceAssert(server.URL(), `
+					ids = ["2"]
+					status = "OK"
+					poll_timeout = "10s"
+					poll_interval = "100ms"
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(assertDS, "passed", "true"),
+					resource.TestCheckResourceAttr(assertDS, "failures.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportAssertInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      makeTestCloudExportDataSourceAssert(server.URL(), `status = "OK"`),
+				ExpectError: regexp.MustCompile(`(?s)one of.*ids,names.*must\s+be specified`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAssert(server.URL(), `
+					ids = ["1"]
+					poll_timeout = "1h"
+				`),
+				ExpectError: regexp.MustCompile(`(?s)expected duration greater than 0 and not longer than\s+5m0s`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAssert(server.URL(), `
+					ids = ["1"]
+					poll_interval = "soon"
+				`),
+				ExpectError: regexp.MustCompile(`invalid duration "soon"`),
+			},
+		},
+	})
+}
+
+func makeTestCloudExportDataSourceAssert(apiURL string, arguments string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+
+		data "kentik-cloudexport_assert" "assert" {
+			%v
+		}
+	`,
+		apiURL, arguments,
+	)
+}
@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+)
+
+// awsFlowLogFields are the VPC flow log fields parsed by Kentik, in the recommended order.
+var awsFlowLogFields = []string{
+	"version", "account-id", "interface-id", "srcaddr", "dstaddr", "srcport", "dstport", "protocol",
+	"packets", "bytes", "start", "end", "action", "log-status", "vpc-id", "subnet-id", "instance-id",
+	"tcp-flags", "type", "pkt-srcaddr", "pkt-dstaddr", "region", "az-id", "sublocation-type", "sublocation-id",
+	"pkt-src-aws-service", "pkt-dst-aws-service", "flow-direction", "traffic-path",
+}
+
+var regexpStartsOrEndsWithSlash = regexp.MustCompile(`^/|/$`)
+
+const (
+	awsFlowLogTrafficType = "ALL"
+	// awsFlowLogAggregationInterval is the shortest interval supported by AWS, in seconds.
+	awsFlowLogAggregationInterval = 60
+	awsFlowLogDestinationType     = "s3"
+	awsFlowLogFileFormat          = "plain-text"
+)
+
+func dataSourceCloudExportAWSFlowLogSettings() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source providing VPC flow log settings recommended for Kentik ingestion, " +
+			"with attributes of aws_flow_log resource. The S3 destination is taken from the AWS export " +
+			"selected by export_id or provided as bucket",
+		ReadContext: dataSourceCloudExportAWSFlowLogSettingsRead,
+		Schema: map[string]*schema.Schema{
+			"export_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "ID of the AWS export to take bucket and region from",
+				ExactlyOneOf: []string{"export_id", awsBucketKey},
+			},
+			awsBucketKey: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "S3 bucket to deliver the flow logs to",
+				ExactlyOneOf:     []string{"export_id", awsBucketKey},
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotEmpty),
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				Description: "AWS region of the bucket, used to derive ARN partition. " +
+					"Taken from the export if export_id is provided",
+				ConflictsWith: []string{"export_id"},
+			},
+			"prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Prefix (folder) in the bucket to deliver the flow logs to, e.g. vpc-flow-logs. " +
+					"The logs are delivered to the bucket root by default",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringDoesNotMatch(
+					regexpStartsOrEndsWithSlash, "prefix must not start or end with /",
+				)),
+			},
+			"log_format": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Custom log format with all the fields parsed by Kentik",
+			},
+			"traffic_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Type of traffic to log, i.e. ALL",
+			},
+			"max_aggregation_interval": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Maximum interval of time (in seconds) to aggregate flows into a record, i.e. 60",
+			},
+			"log_destination_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Type of the logs destination, i.e. s3",
+			},
+			"log_destination": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ARN of S3 destination of the logs, i.e. the bucket with optional prefix",
+			},
+			"file_format": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Format of the log files for destination_options, i.e. plain-text",
+			},
+		},
+	}
+}
+
+func dataSourceCloudExportAWSFlowLogSettingsRead(
+	ctx context.Context, d *schema.ResourceData, m interface{},
+) diag.Diagnostics {
+	bucket := d.Get(awsBucketKey).(string)
+	region := d.Get("region").(string)
+
+	if id, ok := d.GetOk("export_id"); ok {
+		tflog.Debug(ctx, "Get cloud export Kentik API request", map[string]interface{}{"ID": id})
+		export, err := m.(*apiClient).CloudExports.Get(ctx, id.(string))
+		tflog.Debug(ctx, "Get cloud export Kentik API response", map[string]interface{}{"response": export})
+		if err != nil {
+			return detailedDiagError("Failed to read cloud export", err)
+		}
+		aws := export.GetAWSProperties()
+		if export.CloudProvider != models.CloudProviderAWS || aws == nil {
+			return diag.Errorf("cloud export %v is not an AWS export", id)
+		}
+		bucket = aws.Bucket
+		region = aws.Region
+	}
+
+	if bucket == "" {
+		return diag.Errorf("cloud export has no bucket")
+	}
+	destination := makeS3BucketARN(bucket, region)
+	if prefix := d.Get("prefix").(string); prefix != "" {
+		destination += "/" + prefix
+	}
+
+	values := map[string]interface{}{
+		"region":                   region,
+		"log_format":               makeAWSFlowLogFormat(),
+		"traffic_type":             awsFlowLogTrafficType,
+		"max_aggregation_interval": awsFlowLogAggregationInterval,
+		"log_destination_type":     awsFlowLogDestinationType,
+		"log_destination":          destination,
+		"file_format":              awsFlowLogFileFormat,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(fmt.Errorf("set %v: %v", k, err))
+		}
+	}
+	d.SetId(destination)
+
+	return nil
+}
+
+// makeAWSFlowLogFormat returns the custom log format, e.g. "${version} ${account-id} ...".
+func makeAWSFlowLogFormat() string {
+	fields := make([]string, 0, len(awsFlowLogFields))
+	for _, f := range awsFlowLogFields {
+		fields = append(fields, fmt.Sprintf("${%v}", f))
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,114 @@
+package provider_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const awsFlowLogSettingsDS = "data.kentik-cloudexport_aws_flow_log_settings.settings"
+
+func TestDataSourceCloudExportAWSFlowLogSettings(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceAWSFlowLogSettings(server.URL(), `export_id = "1"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(awsFlowLogSettingsDS, "region", "us-east-2"),
+					resource.TestMatchResourceAttr(
+						awsFlowLogSettingsDS, "log_format",
+						regexp.MustCompile(`^\$\{version\} \$\{account-id\} \$\{interface-id\} .* \$\{traffic-path\}$`),
+					),
+					resource.TestCheckResourceAttr(awsFlowLogSettingsDS, "traffic_type", "ALL"),
+					resource.TestCheckResourceAttr(awsFlowLogSettingsDS, "max_aggregation_interval", "60"),
+					resource.TestCheckResourceAttr(awsFlowLogSettingsDS, "log_destination_type", "s3"),
+					resource.TestCheckResourceAttr(
+						awsFlowLogSettingsDS, "log_destination", "arn:aws:s3:::terraform-aws-bucket",
+					),
+					resource.TestCheckResourceAttr(awsFlowLogSettingsDS, "file_format", "plain-text"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAWSFlowLogSettings(server.URL(), `
+					export_id = "1"
+					prefix = "vpc-flow-logs"
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						awsFlowLogSettingsDS, "log_destination", "arn:aws:s3:::terraform-aws-bucket/vpc-flow-logs",
+					),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAWSFlowLogSettings(server.URL(), `
+					bucket = "gov-bucket"
+					region = "us-gov-west-1"
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(awsFlowLogSettingsDS, "log_destination", "arn:aws-us-gov:s3:::gov-bucket"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportAWSFlowLogSettingsErrors(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      makeTestCloudExportDataSourceAWSFlowLogSettings(server.URL(), `export_id = "2"`),
+				ExpectError: regexp.MustCompile(`cloud export 2 is not an AWS export`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceAWSFlowLogSettings(server.URL(), `export_id = "100"`),
+				ExpectError: regexp.MustCompile(`code = NotFound`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAWSFlowLogSettings(server.URL(), `
+					export_id = "1"
+					bucket = "terraform-aws-bucket"
+				`),
+				ExpectError: regexp.MustCompile(`Invalid combination of arguments`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAWSFlowLogSettings(server.URL(), `
+					bucket = "terraform-aws-bucket"
+					prefix = "/logs"
+				`),
+				ExpectError: regexp.MustCompile(`prefix must not start or end with /`),
+			},
+		},
+	})
+}
+
+func makeTestCloudExportDataSourceAWSFlowLogSettings(apiURL string, arguments string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+
+		data "kentik-cloudexport_aws_flow_log_settings" "settings" {
+			%v
+		}
+	`,
+		apiURL, arguments,
+	)
+}
@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	// defaultKentikAWSPrincipal is the IAM role Kentik ingestion uses to assume customer roles.
+	defaultKentikAWSPrincipal = "arn:aws:iam::834693425129:role/eks-ingest-node"
+	iamPolicyVersion          = "2012-10-17"
+)
+
+// awsEC2DescribeActions are the EC2 read-only actions used by Kentik to enrich flow logs with VPC metadata.
+var awsEC2DescribeActions = []string{
+	"ec2:DescribeAvailabilityZones",
+	"ec2:DescribeCustomerGateways",
+	"ec2:DescribeFlowLogs",
+	"ec2:DescribeInstances",
+	"ec2:DescribeInternetGateways",
+	"ec2:DescribeNatGateways",
+	"ec2:DescribeNetworkInterfaces",
+	"ec2:DescribeRegions",
+	"ec2:DescribeRouteTables",
+	"ec2:DescribeSecurityGroups",
+	"ec2:DescribeSubnets",
+	"ec2:DescribeTransitGatewayAttachments",
+	"ec2:DescribeTransitGateways",
+	"ec2:DescribeVpcEndpoints",
+	"ec2:DescribeVpcPeeringConnections",
+	"ec2:DescribeVpcs",
+	"ec2:DescribeVpnConnections",
+	"ec2:DescribeVpnGateways",
+}
+
+func dataSourceCloudExportAWSIAMPolicies() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source providing IAM policies required by Kentik to ingest VPC flow logs of AWS export. " +
+			"The trust policy allows Kentik to assume the role configured as aws.iam_role_arn of the export, " +
+			"the permission policy grants least-privilege access to the S3 buckets and EC2 metadata",
+		ReadContext: dataSourceCloudExportAWSIAMPoliciesRead,
+		Schema: map[string]*schema.Schema{
+			"buckets": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "S3 buckets the export reads flow logs from",
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotEmpty),
+				},
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "AWS region of the buckets. If provided, EC2 permissions are limited to the region " +
+					"and the ARN partition is derived from it (e.g. aws-us-gov for us-gov-west-1)",
+			},
+			"delete_after_read": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the export deletes flow logs after reading, which requires s3:DeleteObject permission",
+			},
+			"kentik_principal_arn": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          defaultKentikAWSPrincipal,
+				Description:      "ARN of the IAM principal used by Kentik to assume the role",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotEmpty),
+			},
+			"trust_policy_json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Trust (assume role) policy of the role, e.g. for assume_role_policy of aws_iam_role",
+			},
+			"permission_policy_json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Permission policy of the role, e.g. for policy of aws_iam_role_policy",
+			},
+		},
+	}
+}
+
+type iamPolicyDocument struct {
+	Version   string               `json:"Version"`
+	Statement []iamPolicyStatement `json:"Statement"`
+}
+
+type iamPolicyStatement struct {
+	Sid       string                       `json:"Sid,omitempty"`
+	Effect    string                       `json:"Effect"`
+	Principal map[string]string            `json:"Principal,omitempty"`
+	Action    []string                     `json:"Action"`
+	Resource  []string                     `json:"Resource,omitempty"`
+	Condition map[string]map[string]string `json:"Condition,omitempty"`
+}
+
+func dataSourceCloudExportAWSIAMPoliciesRead(
+	_ context.Context, d *schema.ResourceData, _ interface{},
+) diag.Diagnostics {
+	var buckets []string
+	for _, b := range d.Get("buckets").([]interface{}) {
+		buckets = append(buckets, b.(string))
+	}
+	region := d.Get("region").(string)
+
+	trustPolicy, err := formatIAMPolicy(makeAWSTrustPolicy(d.Get("kentik_principal_arn").(string)))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	permissionPolicy, err := formatIAMPolicy(
+		makeAWSPermissionPolicy(buckets, region, d.Get("delete_after_read").(bool)),
+	)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("trust_policy_json", trustPolicy); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("permission_policy_json", permissionPolicy); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = setContentHashID(d, []interface{}{trustPolicy, permissionPolicy}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func makeAWSTrustPolicy(principal string) iamPolicyDocument {
+	return iamPolicyDocument{
+		Version: iamPolicyVersion,
+		Statement: []iamPolicyStatement{{
+			Sid:       "KentikAssumeRole",
+			Effect:    "Allow",
+			Principal: map[string]string{"AWS": principal},
+			Action:    []string{"sts:AssumeRole"},
+		}},
+	}
+}
+
+func makeAWSPermissionPolicy(buckets []string, region string, deleteAfterRead bool) iamPolicyDocument {
+	bucketARNs := make([]string, 0, len(buckets))
+	objectARNs := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		bucketARNs = append(bucketARNs, makeS3BucketARN(b, region))
+		objectARNs = append(objectARNs, makeS3BucketARN(b, region)+"/*")
+	}
+
+	objectActions := []string{"s3:GetObject"}
+	if deleteAfterRead {
+		objectActions = append(objectActions, "s3:DeleteObject")
+	}
+
+	ec2Statement := iamPolicyStatement{
+		Sid:      "KentikEC2Metadata",
+		Effect:   "Allow",
+		Action:   awsEC2DescribeActions,
+		Resource: []string{"*"},
+	}
+	if region != "" {
+		ec2Statement.Condition = map[string]map[string]string{
+			"StringEquals": {"aws:RequestedRegion": region},
+		}
+	}
+
+	return iamPolicyDocument{
+		Version: iamPolicyVersion,
+		Statement: []iamPolicyStatement{
+			{
+				Sid:      "KentikS3ListBuckets",
+				Effect:   "Allow",
+				Action:   []string{"s3:GetBucketLocation", "s3:ListBucket"},
+				Resource: bucketARNs,
+			},
+			{
+				Sid:      "KentikS3ReadFlowLogs",
+				Effect:   "Allow",
+				Action:   objectActions,
+				Resource: objectARNs,
+			},
+			ec2Statement,
+		},
+	}
+}
+
+// makeS3BucketARN returns ARN of the bucket in the partition of the region, e.g. arn:aws:s3:::bucket.
+func makeS3BucketARN(bucket string, region string) string {
+	return fmt.Sprintf("arn:%v:s3:::%v", awsPartition(region), bucket)
+}
+
+// awsPartition returns the ARN partition of the region. Standard partition is used for unknown and empty regions.
+func awsPartition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	default:
+		return "aws"
+	}
+}
+
+func formatIAMPolicy(policy iamPolicyDocument) (string, error) {
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return "", fmt.Errorf("marshal IAM policy: %v", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,127 @@
+package provider_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const awsIAMPoliciesDS = "data.kentik-cloudexport_aws_iam_policies.policies"
+
+func TestDataSourceCloudExportAWSIAMPolicies(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceAWSIAMPolicies(server.URL(), `
+					buckets = ["terraform-aws-bucket"]
+					region = "us-east-2"
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						awsIAMPoliciesDS, "trust_policy_json",
+						`{"Version":"2012-10-17","Statement":[{"Sid":"KentikAssumeRole","Effect":"Allow",`+
+							`"Principal":{"AWS":"arn:aws:iam::834693425129:role/eks-ingest-node"},`+
+							`"Action":["sts:AssumeRole"]}]}`,
+					),
+					resource.TestMatchResourceAttr(
+						awsIAMPoliciesDS, "permission_policy_json",
+						regexp.MustCompile(regexp.QuoteMeta(
+							`{"Sid":"KentikS3ListBuckets","Effect":"Allow",`+
+								`"Action":["s3:GetBucketLocation","s3:ListBucket"],`+
+								`"Resource":["arn:aws:s3:::terraform-aws-bucket"]},`+
+								`{"Sid":"KentikS3ReadFlowLogs","Effect":"Allow","Action":["s3:GetObject"],`+
+								`"Resource":["arn:aws:s3:::terraform-aws-bucket/*"]}`,
+						)),
+					),
+					resource.TestMatchResourceAttr(
+						awsIAMPoliciesDS, "permission_policy_json",
+						regexp.MustCompile(regexp.QuoteMeta(
+							`"Condition":{"StringEquals":{"aws:RequestedRegion":"us-east-2"}}`,
+						)),
+					),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAWSIAMPolicies(server.URL(), `
+					buckets = ["bucket-1", "bucket-2"]
+					region = "us-gov-west-1"
+					delete_after_read = true
+					kentik_principal_arn = "arn:aws-us-gov:iam::123456789012:role/kentik"
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						awsIAMPoliciesDS, "trust_policy_json",
+						regexp.MustCompile(regexp.QuoteMeta(
+							`"Principal":{"AWS":"arn:aws-us-gov:iam::123456789012:role/kentik"}`,
+						)),
+					),
+					resource.TestMatchResourceAttr(
+						awsIAMPoliciesDS, "permission_policy_json",
+						regexp.MustCompile(regexp.QuoteMeta(
+							`{"Sid":"KentikS3ReadFlowLogs","Effect":"Allow","Action":["s3:GetObject","s3:DeleteObject"],`+
+								`"Resource":["arn:aws-us-gov:s3:::bucket-1/*","arn:aws-us-gov:s3:::bucket-2/*"]}`,
+						)),
+					),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAWSIAMPolicies(server.URL(), `
+					buckets = ["terraform-aws-bucket"]
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						awsIAMPoliciesDS, "permission_policy_json",
+						regexp.MustCompile(`"Resource":\["\*"\]}\]}$`), // no region condition after EC2 resources
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportAWSIAMPoliciesInvalidBuckets(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      makeTestCloudExportDataSourceAWSIAMPolicies(server.URL(), `buckets = []`),
+				ExpectError: regexp.MustCompile(`Attribute requires 1 item minimum`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceAWSIAMPolicies(server.URL(), `buckets = [""]`),
+				ExpectError: regexp.MustCompile(`expected "buckets" to not be an empty string`),
+			},
+		},
+	})
+}
+
+func makeTestCloudExportDataSourceAWSIAMPolicies(apiURL string, arguments string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+
+		data "kentik-cloudexport_aws_iam_policies" "policies" {
+			%v
+		}
+	`,
+		apiURL, arguments,
+	)
+}
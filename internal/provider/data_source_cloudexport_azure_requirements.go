@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// azureRole is an Azure built-in role required by Kentik.
+type azureRole struct {
+	name string
+	id   string // role definition GUID, same in all subscriptions
+}
+
+var (
+	azureReaderRole              = azureRole{name: "Reader", id: "acdd72a7-3385-48ef-bd42-f606fba81ae7"}
+	azureReaderAndDataAccessRole = azureRole{
+		name: "Reader and Data Access", id: "c12c1c16-33a1-487b-954d-41c89c60f349",
+	}
+	azureStorageBlobDataReaderRole = azureRole{
+		name: "Storage Blob Data Reader", id: "2a2b9908-6ea1-4ae2-8e65-a410df84e7d1",
+	}
+)
+
+func dataSourceCloudExportAzureRequirements() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source providing Azure role assignments required by Kentik service principal " +
+			"to ingest NSG flow logs of Azure export. Kentik needs Reader role on the resource group to read " +
+			"network metadata, and Reader and Data Access and Storage Blob Data Reader roles " +
+			"on the storage account to read the flow logs",
+		ReadContext: dataSourceCloudExportAzureRequirementsRead,
+		Schema: map[string]*schema.Schema{
+			"subscription_id": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "ID of Azure subscription of the export",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+			},
+			"resource_group": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "Resource group containing the storage account and network resources",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotEmpty),
+			},
+			"storage_account": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Storage account the flow logs are stored in",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringMatch(
+					regexp.MustCompile(`^[a-z0-9]{3,24}$`),
+					"storage account name must be 3-24 characters long and contain only lowercase letters and numbers",
+				)),
+			},
+			"principal_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				Description: "Object ID of Kentik service principal in the Azure tenant. " +
+					"It is copied to role_assignments, so that they can be passed directly to azurerm_role_assignment",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+			},
+			"subscription_scope": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ARM ID of the subscription, e.g. /subscriptions/<subscription_id>",
+			},
+			"resource_group_scope": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ARM ID of the resource group",
+			},
+			"storage_account_scope": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ARM ID of the storage account",
+			},
+			"role_assignments": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Description: "Role assignments required by Kentik, with attributes of azurerm_role_assignment. " +
+					"Use key attribute as for_each key",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Unique and stable key of the assignment, e.g. storage_account_reader_and_data_access",
+						},
+						"scope": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ARM ID of the scope of the assignment",
+						},
+						"role_definition_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the built-in role",
+						},
+						"role_definition_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ARM ID of the built-in role definition in the subscription",
+						},
+						"principal_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Object ID of Kentik service principal, as provided in principal_id",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceCloudExportAzureRequirementsRead(
+	_ context.Context, d *schema.ResourceData, _ interface{},
+) diag.Diagnostics {
+	subscriptionID := d.Get("subscription_id").(string)
+	subscriptionScope := makeAzureSubscriptionID(subscriptionID)
+	resourceGroupScope := makeAzureResourceGroupID(subscriptionID, d.Get("resource_group").(string))
+	storageAccountScope := makeAzureStorageAccountID(
+		subscriptionID, d.Get("resource_group").(string), d.Get("storage_account").(string),
+	)
+	principalID := d.Get("principal_id").(string)
+
+	assignment := func(key string, scope string, role azureRole) map[string]interface{} {
+		return map[string]interface{}{
+			"key":                  key,
+			"scope":                scope,
+			"role_definition_name": role.name,
+			"role_definition_id": fmt.Sprintf(
+				"%v/providers/Microsoft.Authorization/roleDefinitions/%v", subscriptionScope, role.id,
+			),
+			"principal_id": principalID,
+		}
+	}
+	assignments := []interface{}{
+		assignment("resource_group_reader", resourceGroupScope, azureReaderRole),
+		assignment("storage_account_reader_and_data_access", storageAccountScope, azureReaderAndDataAccessRole),
+		assignment("storage_account_blob_data_reader", storageAccountScope, azureStorageBlobDataReaderRole),
+	}
+
+	values := map[string]interface{}{
+		"subscription_scope":    subscriptionScope,
+		"resource_group_scope":  resourceGroupScope,
+		"storage_account_scope": storageAccountScope,
+		"role_assignments":      assignments,
+	}
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			return diag.FromErr(fmt.Errorf("set %v: %v", k, err))
+		}
+	}
+	d.SetId(storageAccountScope)
+
+	return nil
+}
+
+func makeAzureSubscriptionID(subscriptionID string) string {
+	return fmt.Sprintf("/subscriptions/%v", subscriptionID)
+}
+
+func makeAzureResourceGroupID(subscriptionID string, resourceGroup string) string {
+	return fmt.Sprintf("%v/resourceGroups/%v", makeAzureSubscriptionID(subscriptionID), resourceGroup)
+}
+
+func makeAzureStorageAccountID(subscriptionID string, resourceGroup string, storageAccount string) string {
+	return fmt.Sprintf(
+		"%v/providers/Microsoft.Storage/storageAccounts/%v",
+		makeAzureResourceGroupID(subscriptionID, resourceGroup), storageAccount,
+	)
+}
@@ -0,0 +1,130 @@
+package provider_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const azureRequirementsDS = "data.kentik-cloudexport_azure_requirements.requirements"
+
+func TestDataSourceCloudExportAzureRequirements(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	const (
+		subscription   = "/subscriptions/784bd5ec-122b-41b7-9719-22f23d5b49c8"
+		resourceGroup  = subscription + "/resourceGroups/traffic-generator"
+		storageAccount = resourceGroup + "/providers/Microsoft.Storage/storageAccounts/kentikstorage"
+		roleDefinition = subscription + "/providers/Microsoft.Authorization/roleDefinitions/"
+	)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceAzureRequirements(server.URL(), `
+					subscription_id = "784bd5ec-122b-41b7-9719-22f23d5b49c8"
+					resource_group = "traffic-generator"
+					storage_account = "kentikstorage"
+					principal_id = "00000000-0000-0000-0000-000000000001"
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(azureRequirementsDS, "id", storageAccount),
+					resource.TestCheckResourceAttr(azureRequirementsDS, "subscription_scope", subscription),
+					resource.TestCheckResourceAttr(azureRequirementsDS, "resource_group_scope", resourceGroup),
+					resource.TestCheckResourceAttr(azureRequirementsDS, "storage_account_scope", storageAccount),
+					resource.TestCheckResourceAttr(azureRequirementsDS, "role_assignments.#", "3"),
+					resource.TestCheckResourceAttr(azureRequirementsDS, "role_assignments.0.key", "resource_group_reader"),
+					resource.TestCheckResourceAttr(azureRequirementsDS, "role_assignments.0.scope", resourceGroup),
+					resource.TestCheckResourceAttr(azureRequirementsDS, "role_assignments.0.role_definition_name", "Reader"),
+					resource.TestCheckResourceAttr(
+						azureRequirementsDS, "role_assignments.0.role_definition_id",
+						roleDefinition+"acdd72a7-3385-48ef-bd42-f606fba81ae7",
+					),
+					resource.TestCheckResourceAttr(
+						azureRequirementsDS, "role_assignments.0.principal_id", "00000000-0000-0000-0000-000000000001",
+					),
+					resource.TestCheckResourceAttr(azureRequirementsDS, "role_assignments.1.scope", storageAccount),
+					resource.TestCheckResourceAttr(
+						azureRequirementsDS, "role_assignments.1.role_definition_name", "Reader and Data Access",
+					),
+					resource.TestCheckResourceAttr(azureRequirementsDS, "role_assignments.2.scope", storageAccount),
+					resource.TestCheckResourceAttr(
+						azureRequirementsDS, "role_assignments.2.role_definition_name", "Storage Blob Data Reader",
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportAzureRequirementsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceAzureRequirements(server.URL(), `
+					subscription_id = "not-uuid"
+					resource_group = "traffic-generator"
+					storage_account = "kentikstorage"
+					principal_id = "00000000-0000-0000-0000-000000000001"
+				`),
+				ExpectError: regexp.MustCompile(`(?s)expected "subscription_id" to be a valid UUID`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAzureRequirements(server.URL(), `
+					subscription_id = "784bd5ec-122b-41b7-9719-22f23d5b49c8"
+					resource_group = "traffic-generator"
+					storage_account = "Kentik-Storage"
+					principal_id = "00000000-0000-0000-0000-000000000001"
+				`),
+				ExpectError: regexp.MustCompile(`(?s)storage account name must be 3-24 characters long`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAzureRequirements(server.URL(), `
+					subscription_id = "784bd5ec-122b-41b7-9719-22f23d5b49c8"
+					resource_group = "traffic-generator"
+					storage_account = "kentikstorage"
+				`),
+				ExpectError: regexp.MustCompile(`(?s)The argument "principal_id" is required`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceAzureRequirements(server.URL(), `
+					subscription_id = "784bd5ec-122b-41b7-9719-22f23d5b49c8"
+					resource_group = "traffic-generator"
+					storage_account = "kentikstorage"
+					principal_id = ""
+				`),
+				ExpectError: regexp.MustCompile(`(?s)expected "principal_id" to be a valid UUID`),
+			},
+		},
+	})
+}
+
+func makeTestCloudExportDataSourceAzureRequirements(apiURL string, arguments string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+
+		data "kentik-cloudexport_azure_requirements" "requirements" {
+			%v
+		}
+	`,
+		apiURL, arguments,
+	)
+}
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+)
+
+func dataSourceCloudExportCallerIdentity() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source providing the Kentik user and company the provider credentials belong to, " +
+			"e.g. to verify that the configuration is applied to the intended account. " +
+			"The user is looked up among the users of the company, which requires the credentials " +
+			"of a user with Administrator role. For other users, user_id, user_full_name and role are empty " +
+			"and the data source reports a warning",
+		ReadContext: dataSourceCloudExportCallerIdentityRead,
+		Schema: map[string]*schema.Schema{
+			"email": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Email of the authenticated user",
+			},
+			"user_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the authenticated user. Empty if the user does not have Administrator role",
+			},
+			"user_full_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Full name of the authenticated user. Empty if the user does not have Administrator role",
+			},
+			"role": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "Role of the authenticated user, e.g. Administrator. " +
+					"Empty if the user does not have Administrator role",
+			},
+			"company_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the Kentik company (account) of the authenticated user",
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "Kentik region of the API: US, EU or custom. " +
+					"The region is derived from the apiurl of the provider",
+			},
+			"api_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Kentik API URL used by the provider, i.e. the configured apiurl or the default one",
+			},
+		},
+	}
+}
+
+func dataSourceCloudExportCallerIdentityRead(
+	ctx context.Context, d *schema.ResourceData, m interface{},
+) diag.Diagnostics {
+	client := m.(*apiClient)
+	values := map[string]interface{}{
+		"email":          client.authEmail,
+		"user_id":        "",
+		"user_full_name": "",
+		"role":           "",
+		"region":         getAPIRegion(client.apiURL),
+		"api_url":        client.apiURL,
+	}
+
+	var diags diag.Diagnostics
+	user, err := findCallerUser(ctx, client)
+	switch {
+	case isPermissionDeniedError(err):
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Caller user details not available",
+			Detail: fmt.Sprintf(
+				"The user %q is not permitted to list the users of the company, which requires Administrator role. "+
+					"user_id, user_full_name and role are left empty", client.authEmail,
+			),
+		})
+		var companyID string
+		if companyID, err = getCallerCompanyID(ctx, client); err != nil {
+			return detailedDiagError("Failed to read caller identity", err)
+		}
+		values["company_id"] = companyID
+	case err != nil:
+		return detailedDiagError("Failed to read caller identity", err)
+	default:
+		values["email"] = user.UserEmail
+		values["user_id"] = user.ID
+		values["user_full_name"] = user.UserFullName
+		values["role"] = user.Role
+		values["company_id"] = user.CompanyID
+	}
+
+	for k, v := range values {
+		if err = d.Set(k, v); err != nil {
+			return diag.FromErr(fmt.Errorf("set %v: %v", k, err))
+		}
+	}
+	d.SetId(values["email"].(string))
+
+	return diags
+}
+
+// findCallerUser looks up the user the client authenticates as.
+// Kentik API does not provide the authenticated user, so it is found by email among the users of the company.
+// Listing the users requires Administrator role.
+func findCallerUser(ctx context.Context, client *apiClient) (*models.User, error) {
+	tflog.Debug(ctx, "Get all users Kentik API request")
+	users, err := client.Users.GetAll(ctx)
+	// the users are not logged, as they may include API tokens
+	tflog.Debug(ctx, "Get all users Kentik API response", map[string]interface{}{"count": len(users)})
+	if err != nil {
+		return nil, makeCallerAPIError(client, "get users", err)
+	}
+
+	for i := range users {
+		if strings.EqualFold(users[i].UserEmail, client.authEmail) {
+			return &users[i], nil
+		}
+	}
+	return nil, fmt.Errorf("user with email %q not found among the users of the company", client.authEmail)
+}
+
+// getCallerCompanyID returns the ID of the company the client authenticates to,
+// without Administrator role required to list the users. Every plan belongs to the company, so the first one is used.
+func getCallerCompanyID(ctx context.Context, client *apiClient) (string, error) {
+	tflog.Debug(ctx, "Get all plans Kentik API request")
+	plans, err := client.Plans.GetAll(ctx)
+	tflog.Debug(ctx, "Get all plans Kentik API response", map[string]interface{}{"response": plans})
+	if err != nil {
+		return "", makeCallerAPIError(client, "get plans", err)
+	}
+	if len(plans) == 0 {
+		return "", fmt.Errorf("company ID cannot be determined, the company of the user %q has no plans", client.authEmail)
+	}
+	return plans[0].CompanyID, nil
+}
+
+// makeCallerAPIError explains the error of Kentik REST API caused by credentials rejected by the API.
+func makeCallerAPIError(client *apiClient, operation string, err error) error {
+	if isUnauthorizedError(err) {
+		return fmt.Errorf(
+			"%v: Kentik API rejected the credentials of the user %q, verify the email and the token: %v",
+			operation, client.authEmail, err,
+		)
+	}
+	return fmt.Errorf("%v: %v", operation, err)
+}
+
+// isPermissionDeniedError reports whether Kentik REST API rejected the request due to insufficient permissions.
+// The API client does not expose the status code, so it is read from the error message.
+func isPermissionDeniedError(err error) bool {
+	return hasHTTPStatus(err, http.StatusForbidden)
+}
+
+// isUnauthorizedError reports whether Kentik REST API rejected the credentials.
+func isUnauthorizedError(err error) bool {
+	return hasHTTPStatus(err, http.StatusUnauthorized)
+}
+
+func hasHTTPStatus(err error, code int) bool {
+	return err != nil && strings.Contains(err.Error(), fmt.Sprintf("status: %v ", code))
+}
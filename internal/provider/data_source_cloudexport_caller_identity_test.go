@@ -0,0 +1,125 @@
+package provider_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const callerIdentityDS = "data.kentik-cloudexport_caller_identity.caller"
+
+func TestDataSourceCloudExportCallerIdentity(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceCallerIdentity(server.URL(), "ADMIN@example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(callerIdentityDS, "id", "admin@example.com"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "email", "admin@example.com"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "user_id", "145999"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "user_full_name", "Admin"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "role", "Administrator"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "company_id", "74333"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "region", "custom"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "api_url", server.URL()),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportCallerIdentityUserNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceCallerIdentity(server.URL(), "jane.doe@example.com"),
+				ExpectError: regexp.MustCompile(
+					`user with email "jane.doe@example.com" not found among the users of the\s+company`,
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportCallerIdentityNonAdministrator(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceCallerIdentity(server.URL(), "joe.doe@example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(callerIdentityDS, "id", "joe.doe@example.com"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "email", "joe.doe@example.com"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "user_id", ""),
+					resource.TestCheckResourceAttr(callerIdentityDS, "user_full_name", ""),
+					resource.TestCheckResourceAttr(callerIdentityDS, "role", ""),
+					resource.TestCheckResourceAttr(callerIdentityDS, "company_id", "74333"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "region", "custom"),
+					resource.TestCheckResourceAttr(callerIdentityDS, "api_url", server.URL()),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportCallerIdentityInvalidCredentials(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceCallerIdentityWithToken(
+					server.URL(), "admin@example.com", "invalid-token",
+				),
+				ExpectError: regexp.MustCompile(
+					`Kentik\s+API\s+rejected\s+the\s+credentials\s+of\s+the\s+user\s+"admin@example.com"`,
+				),
+			},
+		},
+	})
+}
+
+func makeTestCloudExportDataSourceCallerIdentity(apiURL string, email string) string {
+	return makeTestCloudExportDataSourceCallerIdentityWithToken(apiURL, email, "dummy-token")
+}
+
+func makeTestCloudExportDataSourceCallerIdentityWithToken(apiURL string, email string, token string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "%v"
+			token = "%v"
+		}
+
+		data "kentik-cloudexport_caller_identity" "caller" {}
+	`,
+		apiURL, email, token,
+	)
+}
@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/AlekSi/pointer"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+)
+
+func dataSourceCloudExportDevices() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source listing Kentik devices, e.g. to find the device providing BGP data " +
+			"to a cloud export (bgp.use_bgp_device_id attribute)",
+		ReadContext: dataSourceCloudExportDevicesRead,
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only list devices with names matching the regular expression (Go RE2 syntax)",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+			},
+			"bgp_capable": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Description: "Only list devices that can (true) or cannot (false) provide BGP data to a cloud export. " +
+					"See bgp_capable attribute of devices",
+			},
+			"devices": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The devices, sorted by name",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the device",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the device",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Type of the device, e.g. router or host-nprobe-dns-www",
+						},
+						"subtype": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Subtype of the device, e.g. router or kprobe",
+						},
+						"sending_ips": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "IP addresses the device sends flows from",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"plan_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the plan of the device",
+						},
+						"bgp_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+							Description: "BGP configuration of the device: none, device (own BGP session) " +
+								"or other_device (BGP session shared with other device)",
+						},
+						"bgp_configured": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether BGP is configured for the device, i.e. bgp_type is not none",
+						},
+						"bgp_capable": {
+							Type:     schema.TypeBool,
+							Computed: true,
+							Description: "Whether the device can provide BGP data to a cloud export, " +
+								"i.e. it is a router with its own BGP session",
+						},
+					},
+				},
+			},
+			"ids_by_name": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Map of device name to device ID, e.g. to look up bgp.use_bgp_device_id by device name",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// deviceFilter selects the devices to list. Zero value matches all devices.
+type deviceFilter struct {
+	nameRegex  *regexp.Regexp
+	bgpCapable *bool
+}
+
+func getDeviceFilter(d *schema.ResourceData) (*deviceFilter, error) {
+	f := &deviceFilter{bgpCapable: getOptionalBool(d, "bgp_capable")}
+
+	if nameRegex, ok := d.GetOk("name_regex"); ok {
+		r, err := regexp.Compile(nameRegex.(string))
+		if err != nil {
+			return nil, fmt.Errorf("compile name_regex: %v", err)
+		}
+		f.nameRegex = r
+	}
+	return f, nil
+}
+
+func (f *deviceFilter) matches(d *models.Device) bool {
+	switch {
+	case f.nameRegex != nil && !f.nameRegex.MatchString(d.DeviceName):
+		return false
+	case f.bgpCapable != nil && (checkBGPDevice(d) == nil) != *f.bgpCapable:
+		return false
+	}
+	return true
+}
+
+func dataSourceCloudExportDevicesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	filter, err := getDeviceFilter(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	devices, err := getDevices(ctx, m.(*apiClient).Client)
+	if err != nil {
+		return detailedDiagError("Failed to read devices", err)
+	}
+
+	sort.SliceStable(devices, func(i, j int) bool {
+		if devices[i].DeviceName != devices[j].DeviceName {
+			return devices[i].DeviceName < devices[j].DeviceName
+		}
+		return lessID(devices[i].ID, devices[j].ID)
+	})
+
+	items := []interface{}{}
+	idsByName := map[string]interface{}{}
+	for i := range devices {
+		if filter.matches(&devices[i]) {
+			items = append(items, deviceToMap(&devices[i]))
+			idsByName[devices[i].DeviceName] = devices[i].ID
+		}
+	}
+
+	values := map[string]interface{}{
+		"devices":     items,
+		"ids_by_name": idsByName,
+	}
+	for k, v := range values {
+		if err = d.Set(k, v); err != nil {
+			return diag.FromErr(fmt.Errorf("set %v: %v", k, err))
+		}
+	}
+
+	if err = setContentHashID(d, items); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func deviceToMap(d *models.Device) map[string]interface{} {
+	bgpType := ""
+	if d.DeviceBGPType != nil {
+		bgpType = string(*d.DeviceBGPType)
+	}
+	sendingIPs := make([]interface{}, 0, len(d.SendingIPS))
+	for _, ip := range d.SendingIPS {
+		sendingIPs = append(sendingIPs, ip)
+	}
+	return map[string]interface{}{
+		"id":             d.ID,
+		"name":           d.DeviceName,
+		"type":           string(d.DeviceType),
+		"subtype":        string(d.DeviceSubType),
+		"sending_ips":    sendingIPs,
+		"plan_id":        pointer.GetString(d.Plan.ID),
+		"bgp_type":       bgpType,
+		"bgp_configured": isBGPConfigured(d),
+		"bgp_capable":    checkBGPDevice(d) == nil,
+	}
+}
@@ -0,0 +1,102 @@
+package provider_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const devicesDS = "data.kentik-cloudexport_devices.devices"
+
+func TestDataSourceCloudExportDevices(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceDevices(server.URL(), ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(devicesDS, "devices.#", "4"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.id", "1234"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.name", "bgp_router"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.type", "router"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.subtype", "router"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.sending_ips.#", "1"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.sending_ips.0", "10.0.0.1"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.plan_id", "11467"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.bgp_type", "device"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.bgp_configured", "true"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.bgp_capable", "true"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.1.name", "bgp_router_2"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.2.name", "dns_probe"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.2.sending_ips.#", "0"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.2.bgp_capable", "false"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.3.name", "non_bgp_router"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.3.bgp_configured", "false"),
+					resource.TestCheckResourceAttr(devicesDS, "ids_by_name.%", "4"),
+					resource.TestCheckResourceAttr(devicesDS, "ids_by_name.dns_probe", "6666"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceDevices(server.URL(), `bgp_capable = true`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(devicesDS, "devices.#", "2"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.id", "1234"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.1.id", "4444"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceDevices(server.URL(), `
+					name_regex = "router"
+					bgp_capable = false
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(devicesDS, "devices.#", "1"),
+					resource.TestCheckResourceAttr(devicesDS, "devices.0.id", "5555"),
+					resource.TestCheckResourceAttr(devicesDS, "ids_by_name.non_bgp_router", "5555"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportDevicesErrors(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      makeTestCloudExportDataSourceDevices(server.URL(), `name_regex = "["`),
+				ExpectError: regexp.MustCompile(`"name_regex":\s+error parsing regexp`),
+			},
+		},
+	})
+}
+
+func makeTestCloudExportDataSourceDevices(apiURL string, arguments string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+
+		data "kentik-cloudexport_devices" "devices" {
+			%v
+		}
+	`,
+		apiURL, arguments,
+	)
+}
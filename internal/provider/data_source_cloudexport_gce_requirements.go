@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// defaultKentikGCEServiceAccount is the service account Kentik ingestion uses to consume Pub/Sub subscriptions.
+const defaultKentikGCEServiceAccount = "kentik-vpc-flow@kentik-vpc-flow.iam.gserviceaccount.com"
+
+// gceSubscriptionRoles are the roles Kentik needs on the subscription: viewer to verify the subscription
+// and subscriber to pull the flow logs.
+var gceSubscriptionRoles = []string{"roles/pubsub.subscriber", "roles/pubsub.viewer"}
+
+func dataSourceCloudExportGCERequirements() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source providing IAM bindings required by Kentik to consume VPC flow logs " +
+			"from Pub/Sub subscription of GCE export",
+		ReadContext: dataSourceCloudExportGCERequirementsRead,
+		Schema: map[string]*schema.Schema{
+			"project": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "ID of the project of the subscription",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotEmpty),
+			},
+			"subscription": {
+				Type:     schema.TypeString,
+				Required: true,
+				Description: "Name of the Pub/Sub subscription, as configured in gce.subscription of the export. " +
+					"Fully qualified path (projects/<project>/subscriptions/<name>) is also accepted",
+				ValidateDiagFunc: validateGCESubscription,
+			},
+			"kentik_service_account": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          defaultKentikGCEServiceAccount,
+				Description:      "E-mail of the service account used by Kentik to consume the subscription",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotEmpty),
+			},
+			"subscription_path": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Fully qualified path of the subscription, i.e. projects/<project>/subscriptions/<name>",
+			},
+			"member": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "IAM member used by Kentik, i.e. serviceAccount:<kentik_service_account>",
+			},
+			"roles": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Roles Kentik needs on the subscription",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"iam_members": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Description: "Bindings required by Kentik, with attributes of google_pubsub_subscription_iam_member. " +
+					"Use role attribute as for_each key",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"project": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the project of the subscription",
+						},
+						"subscription": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Fully qualified path of the subscription",
+						},
+						"role": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Role to bind",
+						},
+						"member": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "IAM member used by Kentik",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// validateGCESubscription accepts subscription name or fully qualified path.
+func validateGCESubscription(v interface{}, path cty.Path) diag.Diagnostics {
+	s := v.(string)
+	parts := strings.Split(s, "/")
+	valid := s != "" && (len(parts) == 1 ||
+		len(parts) == 4 && parts[0] == "projects" && parts[1] != "" && parts[2] == "subscriptions" && parts[3] != "")
+	if !valid {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Invalid subscription",
+			Detail: fmt.Sprintf(
+				"expected subscription name or path in format projects/<project>/subscriptions/<name>, got %q", s,
+			),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+func dataSourceCloudExportGCERequirementsRead(
+	_ context.Context, d *schema.ResourceData, _ interface{},
+) diag.Diagnostics {
+	project := d.Get("project").(string)
+	subscriptionPath, err := makeGCESubscriptionPath(project, d.Get("subscription").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	member := "serviceAccount:" + d.Get("kentik_service_account").(string)
+
+	roles := make([]interface{}, 0, len(gceSubscriptionRoles))
+	iamMembers := make([]interface{}, 0, len(gceSubscriptionRoles))
+	for _, role := range gceSubscriptionRoles {
+		roles = append(roles, role)
+		iamMembers = append(iamMembers, map[string]interface{}{
+			"project":      project,
+			"subscription": subscriptionPath,
+			"role":         role,
+			"member":       member,
+		})
+	}
+
+	values := map[string]interface{}{
+		"subscription_path": subscriptionPath,
+		"member":            member,
+		"roles":             roles,
+		"iam_members":       iamMembers,
+	}
+	for k, v := range values {
+		if err = d.Set(k, v); err != nil {
+			return diag.FromErr(fmt.Errorf("set %v: %v", k, err))
+		}
+	}
+	d.SetId(subscriptionPath)
+
+	return nil
+}
+
+// makeGCESubscriptionPath returns fully qualified path of the subscription. If the subscription is already
+// a path, it must belong to the project.
+func makeGCESubscriptionPath(project string, subscription string) (string, error) {
+	if !strings.Contains(subscription, "/") {
+		return fmt.Sprintf("projects/%v/subscriptions/%v", project, subscription), nil
+	}
+	if !strings.HasPrefix(subscription, fmt.Sprintf("projects/%v/", project)) {
+		return "", fmt.Errorf("subscription %q does not belong to project %q", subscription, project)
+	}
+	return subscription, nil
+}
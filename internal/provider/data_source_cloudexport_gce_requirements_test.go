@@ -0,0 +1,106 @@
+package provider_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const gceRequirementsDS = "data.kentik-cloudexport_gce_requirements.requirements"
+
+func TestDataSourceCloudExportGCERequirements(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	const (
+		subscriptionPath = "projects/kentik-flow/subscriptions/vpc-flow-logs"
+		kentikMember     = "serviceAccount:kentik-vpc-flow@kentik-vpc-flow.iam.gserviceaccount.com"
+	)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceGCERequirements(server.URL(), `
+					project = "kentik-flow"
+					subscription = "vpc-flow-logs"
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(gceRequirementsDS, "id", subscriptionPath),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "subscription_path", subscriptionPath),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "member", kentikMember),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "roles.#", "2"),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "roles.0", "roles/pubsub.subscriber"),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "roles.1", "roles/pubsub.viewer"),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "iam_members.#", "2"),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "iam_members.0.project", "kentik-flow"),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "iam_members.0.subscription", subscriptionPath),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "iam_members.0.role", "roles/pubsub.subscriber"),
+					resource.TestCheckResourceAttr(gceRequirementsDS, "iam_members.0.member", kentikMember),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceGCERequirements(server.URL(), `
+					project = "kentik-flow"
+					subscription = "projects/kentik-flow/subscriptions/vpc-flow-logs"
+					kentik_service_account = "ingest@example.iam.gserviceaccount.com"
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(gceRequirementsDS, "subscription_path", subscriptionPath),
+					resource.TestCheckResourceAttr(
+						gceRequirementsDS, "member", "serviceAccount:ingest@example.iam.gserviceaccount.com",
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportGCERequirementsInvalidSubscription(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceGCERequirements(server.URL(), `
+					project = "kentik-flow"
+					subscription = "subscriptions/vpc-flow-logs"
+				`),
+				ExpectError: regexp.MustCompile(`expected subscription name or path in format`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceGCERequirements(server.URL(), `
+					project = "kentik-flow"
+					subscription = "projects/other/subscriptions/vpc-flow-logs"
+				`),
+				ExpectError: regexp.MustCompile(`(?s)subscription "projects/other/subscriptions/vpc-flow-logs" does not\s+belong`),
+			},
+		},
+	})
+}
+
+func makeTestCloudExportDataSourceGCERequirements(apiURL string, arguments string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+
+		data "kentik-cloudexport_gce_requirements" "requirements" {
+			%v
+		}
+	`,
+		apiURL, arguments,
+	)
+}
@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+)
+
+const (
+	// healthyStatus is the current_status.status reported for exports working correctly.
+	healthyStatus = "OK"
+	// unknownStatus is used in count_by_status for exports without reported status.
+	unknownStatus = "UNKNOWN"
+)
+
+func dataSourceCloudExportHealth() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source summarizing health of all cloud exports. " +
+			"An export is healthy if its status is OK and none of flow_found, api_access and storage_account_access " +
+			"is reported as false",
+		ReadContext: dataSourceCloudExportHealthRead,
+		Schema: map[string]*schema.Schema{
+			"total_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of exports",
+			},
+			"count_by_status": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Description: "Number of exports by current_status.status, e.g. {\"OK\": 3, \"NOK\": 1}. " +
+					"Exports without reported status are counted as UNKNOWN",
+				Elem: &schema.Schema{Type: schema.TypeInt},
+			},
+			"count_by_cloud_provider": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Number of exports by cloud provider (aws, azure, gce, ibm)",
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"enabled_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of enabled exports",
+			},
+			"disabled_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of disabled exports",
+			},
+			"flow_not_found_ids": makeHealthIDsSchema("IDs of exports with current_status.flow_found = false"),
+			"api_access_missing_ids": makeHealthIDsSchema(
+				"IDs of exports with current_status.api_access = false",
+			),
+			"storage_account_access_missing_ids": makeHealthIDsSchema(
+				"IDs of exports with current_status.storage_account_access = false",
+			),
+			"unhealthy_ids": makeHealthIDsSchema("IDs of exports which are not healthy"),
+			"healthy_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of healthy exports",
+			},
+			"healthy_share": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Share of healthy exports, from 0 to 1. It is 0 if there are no exports",
+			},
+			"invalid_exports_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+				Description: "Number of invalid exports in the account. " +
+					"Invalid exports are not included in the summary",
+			},
+		},
+	}
+}
+
+func makeHealthIDsSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: description,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// cloudExportHealth is the summary of health of cloud exports.
+type cloudExportHealth struct {
+	countByStatus                  map[string]interface{}
+	countByCloudProvider           map[string]interface{}
+	enabledCount                   int
+	flowNotFoundIDs                []interface{}
+	apiAccessMissingIDs            []interface{}
+	storageAccountAccessMissingIDs []interface{}
+	unhealthyIDs                   []interface{}
+}
+
+func newCloudExportHealth(exports []models.CloudExport) *cloudExportHealth {
+	h := &cloudExportHealth{
+		countByStatus:                  map[string]interface{}{},
+		countByCloudProvider:           map[string]interface{}{},
+		flowNotFoundIDs:                []interface{}{},
+		apiAccessMissingIDs:            []interface{}{},
+		storageAccountAccessMissingIDs: []interface{}{},
+		unhealthyIDs:                   []interface{}{},
+	}
+	for i := range exports {
+		h.add(&exports[i])
+	}
+	return h
+}
+
+func (h *cloudExportHealth) add(e *models.CloudExport) {
+	if e.Enabled != nil && *e.Enabled {
+		h.enabledCount++
+	}
+	incrementCount(h.countByCloudProvider, string(e.CloudProvider))
+
+	status := unknownStatus
+	healthy := false
+	if cs := e.CurrentStatus; cs != nil {
+		if cs.Status != "" {
+			status = cs.Status
+		}
+		healthy = cs.Status == healthyStatus
+		if isFalse(cs.FlowFound) {
+			h.flowNotFoundIDs = append(h.flowNotFoundIDs, e.ID)
+			healthy = false
+		}
+		if isFalse(cs.APIAccess) {
+			h.apiAccessMissingIDs = append(h.apiAccessMissingIDs, e.ID)
+			healthy = false
+		}
+		if isFalse(cs.StorageAccountAccess) {
+			h.storageAccountAccessMissingIDs = append(h.storageAccountAccessMissingIDs, e.ID)
+			healthy = false
+		}
+	}
+	incrementCount(h.countByStatus, status)
+
+	if !healthy {
+		h.unhealthyIDs = append(h.unhealthyIDs, e.ID)
+	}
+}
+
+func incrementCount(counts map[string]interface{}, key string) {
+	n, _ := counts[key].(int) // missing key means zero count
+	counts[key] = n + 1
+}
+
+// isFalse reports whether the flag is reported and false. Flags not reported by the server are not considered issues.
+func isFalse(b *bool) bool {
+	return b != nil && !*b
+}
+
+func dataSourceCloudExportHealthRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	tflog.Debug(ctx, "List cloud export Kentik API request")
+	listResp, err := m.(*apiClient).CloudExports.GetAll(ctx)
+	tflog.Debug(ctx, "List cloud export Kentik API response", map[string]interface{}{"response": listResp})
+	if err != nil {
+		return detailedDiagError("Failed to read cloud export health", err)
+	}
+
+	var exports []models.CloudExport
+	var invalidCount uint32
+	if listResp != nil {
+		exports = listResp.CloudExports
+		invalidCount = listResp.InvalidCloudExportsCount
+	}
+	// sort the exports, so that the ID lists are stable
+	sortCloudExports(exports, sortByID)
+	h := newCloudExportHealth(exports)
+
+	total := len(exports)
+	healthyCount := total - len(h.unhealthyIDs)
+	healthyShare := 0.0
+	if total > 0 {
+		healthyShare = float64(healthyCount) / float64(total)
+	}
+
+	values := map[string]interface{}{
+		"total_count":                        total,
+		"count_by_status":                    h.countByStatus,
+		"count_by_cloud_provider":            h.countByCloudProvider,
+		"enabled_count":                      h.enabledCount,
+		"disabled_count":                     total - h.enabledCount,
+		"flow_not_found_ids":                 h.flowNotFoundIDs,
+		"api_access_missing_ids":             h.apiAccessMissingIDs,
+		"storage_account_access_missing_ids": h.storageAccountAccessMissingIDs,
+		"unhealthy_ids":                      h.unhealthyIDs,
+		"healthy_count":                      healthyCount,
+		"healthy_share":                      healthyShare,
+		"invalid_exports_count":              int(invalidCount),
+	}
+	for k, v := range values {
+		if err = d.Set(k, v); err != nil {
+			return diag.FromErr(fmt.Errorf("set %v: %v", k, err))
+		}
+	}
+
+	if err = setContentHashID(d, values); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package provider_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	cloudexportpb "github.com/kentik/api-schema-public/gen/go/kentik/cloud_export/v202101beta1"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const healthDS = "data.kentik-cloudexport_health.health"
+
+func TestDataSourceCloudExportHealth(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeCloudExportsWithAccessIssues())
+	server.invalidExportsCount = 1
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceHealth(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(healthDS, "total_count", "4"),
+					resource.TestCheckResourceAttr(healthDS, "count_by_status.%", "2"),
+					resource.TestCheckResourceAttr(healthDS, "count_by_status.OK", "3"),
+					resource.TestCheckResourceAttr(healthDS, "count_by_status.NOK", "1"),
+					resource.TestCheckResourceAttr(healthDS, "count_by_cloud_provider.%", "4"),
+					resource.TestCheckResourceAttr(healthDS, "count_by_cloud_provider.aws", "1"),
+					resource.TestCheckResourceAttr(healthDS, "count_by_cloud_provider.azure", "1"),
+					resource.TestCheckResourceAttr(healthDS, "count_by_cloud_provider.gce", "1"),
+					resource.TestCheckResourceAttr(healthDS, "count_by_cloud_provider.ibm", "1"),
+					resource.TestCheckResourceAttr(healthDS, "enabled_count", "3"),
+					resource.TestCheckResourceAttr(healthDS, "disabled_count", "1"),
+					resource.TestCheckResourceAttr(healthDS, "flow_not_found_ids.#", "1"),
+					resource.TestCheckResourceAttr(healthDS, "flow_not_found_ids.0", "4"),
+					resource.TestCheckResourceAttr(healthDS, "api_access_missing_ids.#", "1"),
+					resource.TestCheckResourceAttr(healthDS, "api_access_missing_ids.0", "4"),
+					resource.TestCheckResourceAttr(healthDS, "storage_account_access_missing_ids.#", "1"),
+					resource.TestCheckResourceAttr(healthDS, "storage_account_access_missing_ids.0", "3"),
+					resource.TestCheckResourceAttr(healthDS, "unhealthy_ids.#", "3"),
+					resource.TestCheckResourceAttr(healthDS, "unhealthy_ids.0", "2"),
+					resource.TestCheckResourceAttr(healthDS, "unhealthy_ids.1", "3"),
+					resource.TestCheckResourceAttr(healthDS, "unhealthy_ids.2", "4"),
+					resource.TestCheckResourceAttr(healthDS, "healthy_count", "1"),
+					resource.TestCheckResourceAttr(healthDS, "healthy_share", "0.25"),
+					resource.TestCheckResourceAttr(healthDS, "invalid_exports_count", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportHealthNoExports(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, nil)
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceHealth(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(healthDS, "total_count", "0"),
+					resource.TestCheckResourceAttr(healthDS, "count_by_status.%", "0"),
+					resource.TestCheckResourceAttr(healthDS, "unhealthy_ids.#", "0"),
+					resource.TestCheckResourceAttr(healthDS, "healthy_count", "0"),
+					resource.TestCheckResourceAttr(healthDS, "healthy_share", "0"),
+				),
+			},
+		},
+	})
+}
+
+// makeCloudExportsWithAccessIssues returns initial cloud exports, with the disabled IBM export missing
+// storage account access and Azure export missing API access and flow logs.
+func makeCloudExportsWithAccessIssues() []*cloudexportpb.CloudExport {
+	ces := makeInitialCloudExports()
+	for _, ce := range ces {
+		switch ce.Id {
+		case "3":
+			ce.CurrentStatus.StorageAccountAccess = &wrapperspb.BoolValue{Value: false}
+		case "4":
+			ce.CurrentStatus.FlowFound = &wrapperspb.BoolValue{Value: false}
+			ce.CurrentStatus.ApiAccess = &wrapperspb.BoolValue{Value: false}
+			ce.CurrentStatus.StorageAccountAccess = &wrapperspb.BoolValue{Value: true}
+		}
+	}
+	return ces
+}
+
+func makeTestCloudExportDataSourceHealth(apiURL string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+
+		data "kentik-cloudexport_health" "health" {}
+	`,
+		apiURL,
+	)
+}
@@ -2,36 +2,197 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/kentik/community_sdk_golang/kentikapi"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+const (
+	awsBucketLookupKey           = "aws_bucket"
+	azureStorageAccountLookupKey = "azure_storage_account"
+	gceSubscriptionLookupKey     = "gce_subscription"
+	allowMissingKey              = "allow_missing"
+	foundKey                     = "found"
+)
+
+// cloudExportNotFoundError is returned when no export matches the lookup key.
+type cloudExportNotFoundError struct {
+	key   string
+	value string
+}
+
+func (e *cloudExportNotFoundError) Error() string {
+	return fmt.Sprintf("no cloud export with %v %q found", e.key, e.value)
+}
+
 func dataSourceCloudExportItem() *schema.Resource {
 	return &schema.Resource{
-		Description: "Data source representing single cloud export item",
+		Description: "Data source representing single cloud export item. " +
+			"The export is looked up by exactly one of: id, name, aws_bucket, azure_storage_account, gce_subscription. " +
+			"If allow_missing is set, missing export is reported by found attribute instead of an error",
 		ReadContext: dataSourceCloudExportItemRead,
-		Schema:      makeCloudExportSchema(readSingle),
+		Schema:      makeCloudExportItemSchema(),
+	}
+}
+
+func makeCloudExportItemSchema() map[string]*schema.Schema {
+	s := makeCloudExportSchema(readSingle)
+	s["id"].Description = "Look up the export with the internal cloud export identifier, assigned by Kentik"
+	s[awsBucketLookupKey] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Look up the AWS export with the S3 bucket, i.e. aws.bucket equal to the value",
+	}
+	s[azureStorageAccountLookupKey] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Look up the Azure export of the storage account",
+	}
+	s[gceSubscriptionLookupKey] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Look up the GCE export of the Pub/Sub subscription",
+	}
+	s[allowMissingKey] = &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+		Description: "If true, missing export does not cause an error - found is set to false and the export " +
+			"attributes are left empty. Other errors are still reported",
 	}
+	s[foundKey] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Computed:    true,
+		Description: "Whether the export was found",
+	}
+
+	lookupKeys := []string{"id", "name", awsBucketLookupKey, azureStorageAccountLookupKey, gceSubscriptionLookupKey}
+	for _, k := range lookupKeys {
+		s[k].ExactlyOneOf = lookupKeys
+	}
+	return s
 }
 
 func dataSourceCloudExportItemRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	tflog.Debug(ctx, "Get cloud export Kentik API request", map[string]interface{}{"ID": d.Get("id").(string)})
-	export, err := m.(*kentikapi.Client).CloudExports.Get(ctx, d.Get("id").(string))
-	tflog.Debug(ctx, "Get cloud export Kentik API response", map[string]interface{}{"response": export})
+	export, err := lookupCloudExport(ctx, d, m)
 	if err != nil {
+		if d.Get(allowMissingKey).(bool) && isCloudExportNotFound(err) {
+			tflog.Debug(ctx, "Cloud export not found", map[string]interface{}{"error": err.Error()})
+			return setCloudExportItemNotFound(d)
+		}
 		return detailedDiagError("Failed to read cloud export item", err)
 	}
 
 	mapExport := cloudExportToMap(export)
+	mapExport[portalURLKey] = makeCloudExportPortalURL(m.(*apiClient).portalURL, export.ID)
 	for k, v := range mapExport {
 		if err := d.Set(k, v); err != nil {
 			return diag.FromErr(err)
 		}
 	}
+	if err := d.Set(foundKey, true); err != nil {
+		return diag.FromErr(err)
+	}
 	d.SetId(export.ID)
 
 	return nil
 }
+
+// setCloudExportItemNotFound leaves the export attributes empty. The ID is derived from the lookup key,
+// so that the id attribute keeps the configured value when looked up by ID.
+func setCloudExportItemNotFound(d *schema.ResourceData) diag.Diagnostics {
+	if err := d.Set(foundKey, false); err != nil {
+		return diag.FromErr(err)
+	}
+	if id, ok := d.GetOk("id"); ok {
+		d.SetId(id.(string))
+		return nil
+	}
+	key, value, _ := cloudExportLookup(d)
+	d.SetId(fmt.Sprintf("%v:%v", key, value))
+	return nil
+}
+
+// isCloudExportNotFound reports whether the error is caused by the export not existing, as opposed to
+// e.g. connectivity or permission issues.
+func isCloudExportNotFound(err error) bool {
+	var notFoundErr *cloudExportNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return true
+	}
+	return status.Code(err) == codes.NotFound
+}
+
+// lookupCloudExport gets the export by ID or, if other lookup key is provided, finds the export in the list of all
+// exports, as Kentik API does not allow to filter exports.
+func lookupCloudExport(ctx context.Context, d *schema.ResourceData, m interface{}) (*models.CloudExport, error) {
+	if id, ok := d.GetOk("id"); ok {
+		tflog.Debug(ctx, "Get cloud export Kentik API request", map[string]interface{}{"ID": id})
+		export, err := m.(*apiClient).CloudExports.Get(ctx, id.(string))
+		tflog.Debug(ctx, "Get cloud export Kentik API response", map[string]interface{}{"response": export})
+		if err != nil {
+			return nil, err //nolint: wrapcheck // preserve gRPC status of the error
+		}
+		return export, nil
+	}
+
+	key, value, matches := cloudExportLookup(d)
+
+	tflog.Debug(ctx, "List cloud export Kentik API request")
+	listResp, err := m.(*apiClient).CloudExports.GetAll(ctx)
+	tflog.Debug(ctx, "List cloud export Kentik API response", map[string]interface{}{"response": listResp})
+	if err != nil {
+		return nil, fmt.Errorf("get cloud export list: %v", err)
+	}
+
+	var found []*models.CloudExport
+	var ids []string
+	for i := range listResp.CloudExports {
+		if matches(&listResp.CloudExports[i]) {
+			found = append(found, &listResp.CloudExports[i])
+			ids = append(ids, listResp.CloudExports[i].ID)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, &cloudExportNotFoundError{key: key, value: value}
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf(
+			"%v cloud exports with %v %q found (IDs: %v), use id to select one of them",
+			len(found), key, value, strings.Join(ids, ", "),
+		)
+	}
+}
+
+// cloudExportLookup returns the lookup key provided by the user, its value and the function matching the exports.
+func cloudExportLookup(d *schema.ResourceData) (key string, value string, matches func(*models.CloudExport) bool) {
+	if v, ok := d.GetOk(awsBucketLookupKey); ok {
+		return awsBucketLookupKey, v.(string), func(e *models.CloudExport) bool {
+			return e.GetAWSProperties() != nil && e.GetAWSProperties().Bucket == v.(string)
+		}
+	}
+	if v, ok := d.GetOk(azureStorageAccountLookupKey); ok {
+		return azureStorageAccountLookupKey, v.(string), func(e *models.CloudExport) bool {
+			return e.GetAzureProperties() != nil && e.GetAzureProperties().StorageAccount == v.(string)
+		}
+	}
+	if v, ok := d.GetOk(gceSubscriptionLookupKey); ok {
+		return gceSubscriptionLookupKey, v.(string), func(e *models.CloudExport) bool {
+			return e.GetGCEProperties() != nil && e.GetGCEProperties().Subscription == v.(string)
+		}
+	}
+	name := d.Get("name").(string)
+	return "name", name, func(e *models.CloudExport) bool {
+		return e.Name == name
+	}
+}
@@ -5,22 +5,26 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/AlekSi/pointer"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	cloudexportpb "github.com/kentik/api-schema-public/gen/go/kentik/cloud_export/v202101beta1"
 	"github.com/kentik/community_sdk_golang/kentikapi"
 	"github.com/kentik/community_sdk_golang/kentikapi/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 )
 
 const (
-	ceAWSDS   = "data.kentik-cloudexport_item.aws"
-	ceAzureDS = "data.kentik-cloudexport_item.azure"
-	ceGCPDS   = "data.kentik-cloudexport_item.gce"
-	ceIBMDS   = "data.kentik-cloudexport_item.ibm"
+	ceAWSDS    = "data.kentik-cloudexport_item.aws"
+	ceAzureDS  = "data.kentik-cloudexport_item.azure"
+	ceGCPDS    = "data.kentik-cloudexport_item.gce"
+	ceIBMDS    = "data.kentik-cloudexport_item.ibm"
+	ceLookupDS = "data.kentik-cloudexport_item.lookup"
 )
 
 //nolint: gochecknoinits
@@ -87,6 +91,10 @@ func TestDataSourceCloudExportItemAWS(t *testing.T) {
 					resource.TestCheckResourceAttr(ceAWSDS, "aws.0.region", "us-east-2"),
 					resource.TestCheckResourceAttr(ceAWSDS, "aws.0.delete_after_read", "false"),
 					resource.TestCheckResourceAttr(ceAWSDS, "aws.0.multiple_buckets", "false"),
+					resource.TestCheckResourceAttr(ceAWSDS, "aws_account_id", "003740049406"),
+					resource.TestCheckResourceAttr(ceAWSDS, "aws_role_name", "trafficTerraformIngestRole"),
+					resource.TestCheckResourceAttr(ceAWSDS, "aws_bucket_arn", "arn:aws:s3:::terraform-aws-bucket"),
+					resource.TestCheckResourceAttr(ceAWSDS, "portal_url", ""),
 				),
 			},
 		},
@@ -120,6 +128,9 @@ func TestDataSourceCloudExportItemGCE(t *testing.T) {
 					resource.TestCheckResourceAttr(ceGCPDS, "current_status.0.storage_account_access", "false"),
 					resource.TestCheckResourceAttr(ceGCPDS, "gce.0.project", "project gce"),
 					resource.TestCheckResourceAttr(ceGCPDS, "gce.0.subscription", "subscription gce"),
+					resource.TestCheckResourceAttr(
+						ceGCPDS, "gce_subscription_path", "projects/project gce/subscriptions/subscription gce",
+					),
 				),
 			},
 		},
@@ -188,12 +199,231 @@ func TestDataSourceCloudExportItemAzure(t *testing.T) {
 					resource.TestCheckResourceAttr(ceAzureDS, "azure.0.storage_account", "kentikstorage"),
 					resource.TestCheckResourceAttr(ceAzureDS, "azure.0.subscription_id", "784bd5ec-122b-41b7-9719-22f23d5b49c8"),
 					resource.TestCheckResourceAttr(ceAzureDS, "azure.0.security_principal_enabled", "true"),
+					resource.TestCheckResourceAttr(
+						ceAzureDS,
+						"azure_storage_account_id",
+						"/subscriptions/784bd5ec-122b-41b7-9719-22f23d5b49c8/resourceGroups/traffic-generator"+
+							"/providers/Microsoft.Storage/storageAccounts/kentikstorage",
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportItemLookup(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeCloudExportsWithSharedBucket())
+	server.Start()
+	defer server.Stop()
+
+	tests := []struct {
+		name       string
+		lookup     string
+		expectedID string
+	}{
+		{
+			name:       "ID",
+			lookup:     `id = "2"`,
+			expectedID: "2",
+		}, {
+			name:       "name",
+			lookup:     `name = "test_terraform_ibm_export"`,
+			expectedID: "3",
+		}, {
+			name:       "AWS bucket",
+			lookup:     `aws_bucket = "terraform-aws-bucket-2"`,
+			expectedID: "5",
+		}, {
+			name:       "Azure storage account",
+			lookup:     `azure_storage_account = "kentikstorage"`,
+			expectedID: "4",
+		}, {
+			name:       "GCE subscription",
+			lookup:     `gce_subscription = "subscription gce"`,
+			expectedID: "2",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				ProviderFactories: providerFactories(),
+				Steps: []resource.TestStep{
+					{
+						Config: makeTestCloudExportDataSourceItemLookup(server.URL(), tt.lookup),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr(ceLookupDS, "id", tt.expectedID),
+							resource.TestCheckResourceAttrSet(ceLookupDS, "name"),
+							resource.TestCheckResourceAttrSet(ceLookupDS, "cloud_provider"),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestDataSourceCloudExportItemLookupErrors(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeCloudExportsWithSharedBucket())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      makeTestCloudExportDataSourceItemLookup(server.URL(), `name = "missing_export"`),
+				ExpectError: regexp.MustCompile(`no cloud export with name "missing_export" found`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceItemLookup(server.URL(), `gce_subscription = "missing"`),
+				ExpectError: regexp.MustCompile(`no cloud export with gce_subscription "missing" found`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceItemLookup(server.URL(), `aws_bucket = "terraform-aws"`),
+				ExpectError: regexp.MustCompile(`no cloud export with aws_bucket "terraform-aws" found`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceItemLookup(server.URL(), `aws_bucket = "terraform-aws-bucket"`),
+				ExpectError: regexp.MustCompile(`2 cloud exports with aws_bucket "terraform-aws-bucket" found \(IDs: 1, 6\)`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceItemLookup(server.URL(), ""),
+				ExpectError: regexp.MustCompile(`(?s)one of.*id,name.*must\s+be specified`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceItemLookup(server.URL(), `id = "1"
+					name = "test_terraform_aws_export"`,
+				),
+				ExpectError: regexp.MustCompile(`(?s)only one of.*id,name.*can\s+be specified`),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportItemAllowMissing(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceItemLookup(server.URL(), `id = "100"
+					allow_missing = true`,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceLookupDS, "id", "100"),
+					resource.TestCheckResourceAttr(ceLookupDS, "found", "false"),
+					resource.TestCheckNoResourceAttr(ceLookupDS, "name"),
+					resource.TestCheckNoResourceAttr(ceLookupDS, "cloud_provider"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceItemLookup(server.URL(), `gce_subscription = "missing"
+					allow_missing = true`,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceLookupDS, "found", "false"),
+					resource.TestCheckNoResourceAttr(ceLookupDS, "name"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceItemLookup(server.URL(), `id = "1"
+					allow_missing = true`,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceLookupDS, "id", "1"),
+					resource.TestCheckResourceAttr(ceLookupDS, "found", "true"),
+					resource.TestCheckResourceAttr(ceLookupDS, "name", "test_terraform_aws_export"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportItemAllowMissingFailsOnOtherErrors(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.getErrorCode = codes.PermissionDenied
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceItemLookup(server.URL(), `id = "1"
+					allow_missing = true`,
 				),
+				ExpectError: regexp.MustCompile(`code = PermissionDenied`),
 			},
 		},
 	})
 }
 
+// makeCloudExportsWithSharedBucket returns initial cloud exports and two AWS exports,
+// one reading from other bucket and one reading from the bucket of the initial AWS export.
+func makeCloudExportsWithSharedBucket() []*cloudexportpb.CloudExport {
+	ces := makeInitialCloudExports()
+	return append(ces,
+		&cloudexportpb.CloudExport{
+			Id:            "5",
+			Type:          cloudexportpb.CloudExportType_CLOUD_EXPORT_TYPE_KENTIK_MANAGED,
+			Enabled:       true,
+			Name:          "test_terraform_aws_export_2",
+			PlanId:        "11467",
+			CloudProvider: "aws",
+			Properties: &cloudexportpb.CloudExport_Aws{
+				Aws: &cloudexportpb.AwsProperties{
+					Bucket:     "terraform-aws-bucket-2",
+					IamRoleArn: "arn:aws:iam::003740049406:role/trafficTerraformIngestRole",
+					Region:     "us-east-2",
+				},
+			},
+		},
+		&cloudexportpb.CloudExport{
+			Id:            "6",
+			Type:          cloudexportpb.CloudExportType_CLOUD_EXPORT_TYPE_KENTIK_MANAGED,
+			Enabled:       true,
+			Name:          "test_terraform_aws_shared_bucket_export",
+			PlanId:        "11467",
+			CloudProvider: "aws",
+			Properties: &cloudexportpb.CloudExport_Aws{
+				Aws: &cloudexportpb.AwsProperties{
+					Bucket:     "terraform-aws-bucket",
+					IamRoleArn: "arn:aws:iam::003740049406:role/trafficTerraformIngestRole",
+					Region:     "us-west-1",
+				},
+			},
+		},
+	)
+}
+
+func makeTestCloudExportDataSourceItemLookup(apiURL string, lookup string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+		  
+		data "kentik-cloudexport_item" "lookup" {
+			%v
+		}
+	`,
+		apiURL, lookup,
+	)
+}
+
 func makeTestCloudExportDataSourceItems(apiURL string) string {
 	return fmt.Sprintf(`
 		provider "kentik-cloudexport" {
@@ -443,6 +673,8 @@ func checkRequiredEnvVariables(t *testing.T) {
 	require.True(t, ok, "KTAPI_URL env variable not set")
 	_, ok = os.LookupEnv("KENTIK_PLAN_ID")
 	require.True(t, ok, "KENTIK_PLAN_ID env variable not set")
+	_, ok = os.LookupEnv("KENTIK_BGP_DEVICE_ID")
+	require.True(t, ok, "KENTIK_BGP_DEVICE_ID env variable not set")
 }
 
 func makeTestAccCloudExportDataSourceItems(provider string, ce *models.CloudExport) string {
@@ -543,6 +775,12 @@ func getKentikPlanIDAccTests() string {
 	return planID
 }
 
+// getKentikBGPDeviceIDAccTests returns ID of a router with BGP enabled, which can be referenced by cloud exports.
+func getKentikBGPDeviceIDAccTests() string {
+	deviceID, _ := os.LookupEnv("KENTIK_BGP_DEVICE_ID")
+	return deviceID
+}
+
 func newClient() (*kentikapi.Client, error) {
 	authEmail, _ := os.LookupEnv("KTAPI_AUTH_EMAIL")
 	authToken, _ := os.LookupEnv("KTAPI_AUTH_TOKEN")
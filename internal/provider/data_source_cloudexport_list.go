@@ -2,13 +2,19 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
-	"time"
 
+	"github.com/google/cel-go/cel"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/kentik/community_sdk_golang/kentikapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
 )
 
 func dataSourceCloudExportList() *schema.Resource {
@@ -19,37 +25,462 @@ func dataSourceCloudExportList() *schema.Resource {
 			"items": {
 				Type:     schema.TypeList,
 				Computed: true,
+				Description: "The exports with all their attributes. " +
+					"Empty if attributes are selected, as the exports are then provided in items_json",
 				Elem: &schema.Resource{
 					Schema: makeCloudExportSchema(readList),
 				},
 			},
+			"items_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "JSON-encoded list of the exports containing only the attributes selected with attributes, " +
+					"e.g. jsondecode(items_json)[0].current_status[0].status. Unlike in items, the attributes that are " +
+					"not selected are omitted. Empty if no attributes are selected",
+			},
+			"invalid_exports_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+				Description: "Number of invalid exports in the account. " +
+					"Invalid exports are not listed in items, the data source reports a warning if there are any",
+			},
+			"ids_by_name": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Map from name to ID of the listed exports",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"names": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Names of the listed exports",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			// Note: maps of lists are not supported by Terraform SDK, so a single-item list of object is used instead.
+			"by_cloud_provider": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Description: "IDs of the listed exports grouped by cloud provider, " +
+					"e.g. by_cloud_provider[0].aws is the list of IDs of AWS exports",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						awsKey:   makeCloudProviderIDsSchema("AWS"),
+						azureKey: makeCloudProviderIDsSchema("Azure"),
+						gceKey:   makeCloudProviderIDsSchema("GCE"),
+						ibmKey:   makeCloudProviderIDsSchema("IBM"),
+					},
+				},
+			},
+			"attributes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Description: "Attributes of the exports to include, e.g. [\"id\", \"name\", \"current_status\"]. " +
+					"If selected, the exports are provided in items_json instead of items, so that the attributes " +
+					"that are not selected are omitted from Terraform state. Limiting the attributes reduces " +
+					"the size of Terraform state and plans for accounts with many exports. " +
+					"Default: all attributes, provided in items",
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(cloudExportAttributes(), false)),
+				},
+			},
+			"sort_by": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  sortByName,
+				Description: "Attribute to sort items by: name, id, cloud_provider or plan_id. " +
+					"Items with equal values are sorted by name and then by ID. Default: name",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(
+					[]string{sortByName, sortByID, sortByCloudProvider, sortByPlanID}, false,
+				)),
+			},
+			"cloud_provider": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list exports targeting the cloud provider (aws, azure, gce, ibm)",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(
+					[]string{
+						models.CloudProviderAWS,
+						models.CloudProviderAzure,
+						models.CloudProviderGCE,
+						models.CloudProviderIBM,
+					}, false)),
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list exports of the type, e.g. CLOUD_EXPORT_TYPE_KENTIK_MANAGED",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(
+					[]string{
+						models.CloudExportTypeUnspecified,
+						models.CloudExportTypeKentikManaged,
+						models.CloudExportTypeCustomerManaged,
+					}, false)),
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only list enabled (true) or disabled (false) exports",
+			},
+			"plan_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list exports associated with the Kentik plan",
+			},
+			"name_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only list exports with names matching the regular expression (Go RE2 syntax)",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+			},
+			"aws_bucket": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list AWS exports with the S3 bucket, i.e. aws.bucket equal to the value",
+			},
+			"aws_region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list AWS exports with the bucket residing in the region",
+			},
+			"azure_subscription_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list Azure exports of the subscription",
+			},
+			"gce_project": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list GCE exports of the project",
+			},
+			"where": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "Only list exports matching the expression in Common Expression Language " +
+					"(see: <https://github.com/google/cel-spec>). An export, with the attributes listed in items, " +
+					"is available as export variable, e.g. \"export.enabled && export.cloud_provider == 'aws' && " +
+					"export.aws[0].region.startsWith('us-') && export.current_status[0].status != 'OK'\". " +
+					"Nested objects absent for an export can be detected with has() macro, e.g. has(export.bgp). " +
+					"An export does not match if the expression accesses a nested object absent for it, " +
+					"e.g. export.aws[0] of a GCE export. Other evaluation errors, e.g. of a misspelt attribute, " +
+					"fail the read",
+				ValidateDiagFunc: validateWhereExpression,
+			},
 		},
 	}
 }
 
+func makeCloudProviderIDsSchema(cloudProvider string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: fmt.Sprintf("IDs of the listed %v exports", cloudProvider),
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+const (
+	sortByName          = "name"
+	sortByID            = "id"
+	sortByCloudProvider = "cloud_provider"
+	sortByPlanID        = "plan_id"
+)
+
+// cloudExportListFilter selects the exports matching all the provided criteria.
+type cloudExportListFilter struct {
+	cloudProvider       string
+	exportType          string
+	enabled             *bool
+	planID              string
+	nameRegex           *regexp.Regexp
+	awsBucket           string
+	awsRegion           string
+	azureSubscriptionID string
+	gceProject          string
+	where               cel.Program
+	portalURL           string // used to evaluate where expression on portal_url
+}
+
+func newCloudExportListFilter(d *schema.ResourceData, portalURL string) (*cloudExportListFilter, error) {
+	f := &cloudExportListFilter{
+		cloudProvider:       d.Get("cloud_provider").(string),
+		exportType:          d.Get("type").(string),
+		enabled:             getOptionalBool(d, "enabled"),
+		planID:              d.Get("plan_id").(string),
+		awsBucket:           d.Get("aws_bucket").(string),
+		awsRegion:           d.Get("aws_region").(string),
+		azureSubscriptionID: d.Get("azure_subscription_id").(string),
+		gceProject:          d.Get("gce_project").(string),
+		portalURL:           portalURL,
+	}
+
+	if nameRegex, ok := d.GetOk("name_regex"); ok {
+		r, err := regexp.Compile(nameRegex.(string))
+		if err != nil {
+			return nil, fmt.Errorf("compile name_regex: %v", err)
+		}
+		f.nameRegex = r
+	}
+
+	if where, ok := d.GetOk("where"); ok {
+		program, err := compileWhereExpression(where.(string))
+		if err != nil {
+			return nil, fmt.Errorf("compile where expression: %v", err)
+		}
+		f.where = program
+	}
+	return f, nil
+}
+
+// matches reports whether the export matches the filter. The error is returned if where expression fails.
+func (f *cloudExportListFilter) matches(e *models.CloudExport) (bool, error) {
+	switch {
+	case f.cloudProvider != "" && string(e.CloudProvider) != f.cloudProvider,
+		f.exportType != "" && string(e.Type) != f.exportType,
+		f.enabled != nil && (e.Enabled == nil || *e.Enabled != *f.enabled),
+		f.planID != "" && e.PlanID != f.planID,
+		f.nameRegex != nil && !f.nameRegex.MatchString(e.Name),
+		!f.matchesProperties(e):
+		return false, nil
+	}
+	if f.where != nil {
+		return evaluateWhereExpression(f.where, cloudExportToItem(e, f.portalURL))
+	}
+	return true, nil
+}
+
+func (f *cloudExportListFilter) matchesProperties(e *models.CloudExport) bool {
+	if f.awsBucket != "" || f.awsRegion != "" {
+		aws := e.GetAWSProperties()
+		if aws == nil || (f.awsRegion != "" && aws.Region != f.awsRegion) {
+			return false
+		}
+		if f.awsBucket != "" && aws.Bucket != f.awsBucket {
+			return false
+		}
+	}
+
+	if f.azureSubscriptionID != "" {
+		azure := e.GetAzureProperties()
+		if azure == nil || azure.SubscriptionID != f.azureSubscriptionID {
+			return false
+		}
+	}
+
+	if f.gceProject != "" {
+		gce := e.GetGCEProperties()
+		if gce == nil || gce.Project != f.gceProject {
+			return false
+		}
+	}
+	return true
+}
+
 func dataSourceCloudExportListRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	tflog.Debug(ctx, "List cloud export Kentik API request")
-	listResp, err := m.(*kentikapi.Client).CloudExports.GetAll(ctx)
+	listResp, err := m.(*apiClient).CloudExports.GetAll(ctx)
 	tflog.Debug(ctx, "List cloud export Kentik API response", map[string]interface{}{"response": listResp})
 	if err != nil {
 		return detailedDiagError("Failed to read cloud export list", err)
 	}
 
+	filter, err := newCloudExportListFilter(d, m.(*apiClient).portalURL)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+	var exports []models.CloudExport
 	if listResp != nil {
-		numExports := len(listResp.CloudExports)
-		exports := make([]interface{}, numExports)
-		for i, e := range listResp.CloudExports {
-			ee := e // avoid implicit memory aliasing in for loop (G601)
-			exports[i] = cloudExportToMap(&ee)
+		if err = d.Set("invalid_exports_count", int(listResp.InvalidCloudExportsCount)); err != nil {
+			return diag.FromErr(err)
+		}
+		if listResp.InvalidCloudExportsCount > 0 {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Invalid cloud exports found",
+				Detail: fmt.Sprintf(
+					"Kentik API reported %v invalid cloud export(s), which are not included in the list. "+
+						"Review the cloud exports in Kentik portal",
+					listResp.InvalidCloudExportsCount,
+				),
+			})
 		}
 
-		if err = d.Set("items", exports); err != nil {
-			return diag.FromErr(err)
+		if exports, err = filterCloudExports(listResp.CloudExports, filter); err != nil {
+			return append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Failed to evaluate where expression",
+				Detail:        err.Error(),
+				AttributePath: cty.GetAttrPath("where"),
+			})
 		}
 	}
+	sortCloudExports(exports, d.Get("sort_by").(string))
 
-	// use UNIX time as ID to force list update every time Terraform asks for the list
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	attributes := d.Get("attributes").(*schema.Set)
+	items := cloudExportsToItems(exports, attributes, m.(*apiClient).portalURL)
+	if err = setCloudExportListItems(d, items, attributes.Len() > 0); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = setCloudExportListIndexes(d, exports); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = setContentHashID(d, items); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
 
+// filterCloudExports returns the exports matching the filter.
+// The error reports the number of exports for which where expression failed and the first failure.
+func filterCloudExports(exports []models.CloudExport, filter *cloudExportListFilter) ([]models.CloudExport, error) {
+	var matching []models.CloudExport
+	var firstErr error
+	errCount := 0
+	for i := range exports {
+		match, err := filter.matches(&exports[i])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("cloud export %q (ID %v): %v", exports[i].Name, exports[i].ID, err)
+			}
+			errCount++
+		}
+		if match {
+			matching = append(matching, exports[i])
+		}
+	}
+	if firstErr != nil {
+		return nil, fmt.Errorf("the expression could not be evaluated for %v cloud export(s), first error: %v",
+			errCount, firstErr)
+	}
+	return matching, nil
+}
+
+// cloudExportAttributes returns the names of top-level attributes of an export.
+func cloudExportAttributes() []string {
+	var attributes []string
+	for k := range makeCloudExportSchema(readList) {
+		attributes = append(attributes, k)
+	}
+	sort.Strings(attributes)
+	return attributes
+}
+
+// cloudExportToItem converts the export into an item with all attributes.
+func cloudExportToItem(e *models.CloudExport, portalURL string) map[string]interface{} {
+	item := cloudExportToMap(e)
+	item[portalURLKey] = makeCloudExportPortalURL(portalURL, e.ID)
+	return item
+}
+
+// cloudExportsToItems converts the exports into items containing only the selected attributes.
+// All attributes are included if none is selected.
+func cloudExportsToItems(exports []models.CloudExport, attributes *schema.Set, portalURL string) []interface{} {
+	items := make([]interface{}, len(exports))
+	for i := range exports {
+		item := cloudExportToItem(&exports[i], portalURL)
+		if attributes.Len() > 0 {
+			for k := range item {
+				if !attributes.Contains(k) {
+					delete(item, k)
+				}
+			}
+		}
+		items[i] = item
+	}
+	return items
+}
+
+// setCloudExportListItems sets the items, or items_json if they contain only the selected attributes.
+// Terraform state contains all the attributes of items, the ones missing in an item are stored with empty values.
+// The JSON representation omits them, so that selecting the attributes actually reduces the size of the state.
+func setCloudExportListItems(d *schema.ResourceData, items []interface{}, projected bool) error {
+	itemsJSON := ""
+	if projected {
+		b, err := json.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("marshal items: %v", err)
+		}
+		itemsJSON = string(b)
+		items = []interface{}{}
+	}
+
+	if err := d.Set("items", items); err != nil {
+		return fmt.Errorf("set items: %v", err)
+	}
+	if err := d.Set("items_json", itemsJSON); err != nil {
+		return fmt.Errorf("set items_json: %v", err)
+	}
+	return nil
+}
+
+// setCloudExportListIndexes sets the attributes providing the listed exports in map-like shapes.
+func setCloudExportListIndexes(d *schema.ResourceData, exports []models.CloudExport) error {
+	idsByName := make(map[string]interface{}, len(exports))
+	names := make([]interface{}, 0, len(exports))
+	byCloudProvider := map[string]interface{}{
+		awsKey:   []interface{}{},
+		azureKey: []interface{}{},
+		gceKey:   []interface{}{},
+		ibmKey:   []interface{}{},
+	}
+	for _, e := range exports {
+		idsByName[e.Name] = e.ID
+		names = append(names, e.Name)
+		if ids, ok := byCloudProvider[string(e.CloudProvider)].([]interface{}); ok {
+			byCloudProvider[string(e.CloudProvider)] = append(ids, e.ID)
+		}
+	}
+
+	if err := d.Set("ids_by_name", idsByName); err != nil {
+		return fmt.Errorf("set ids_by_name: %v", err)
+	}
+	if err := d.Set("names", names); err != nil {
+		return fmt.Errorf("set names: %v", err)
+	}
+	if err := d.Set("by_cloud_provider", []interface{}{byCloudProvider}); err != nil {
+		return fmt.Errorf("set by_cloud_provider: %v", err)
+	}
 	return nil
 }
+
+// sortCloudExports sorts the exports by the attribute and then by name and ID, making the order deterministic.
+func sortCloudExports(exports []models.CloudExport, sortBy string) {
+	byNameAndID := func(a, b *models.CloudExport) bool {
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return lessID(a.ID, b.ID)
+	}
+
+	sort.SliceStable(exports, func(i, j int) bool {
+		a, b := &exports[i], &exports[j]
+		switch sortBy {
+		case sortByID:
+			return lessID(a.ID, b.ID)
+		case sortByCloudProvider:
+			if a.CloudProvider != b.CloudProvider {
+				return a.CloudProvider < b.CloudProvider
+			}
+		case sortByPlanID:
+			if a.PlanID != b.PlanID {
+				return lessID(a.PlanID, b.PlanID)
+			}
+		}
+		return byNameAndID(a, b)
+	})
+}
+
+// lessID compares numeric IDs by value and other IDs lexicographically.
+func lessID(a, b string) bool {
+	x, errA := strconv.ParseInt(a, 10, 64)
+	y, errB := strconv.ParseInt(b, 10, 64)
+	if errA != nil || errB != nil || x == y {
+		return a < b
+	}
+	return x < y
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+)
+
+// benchmarkExportsCountEnv sets the number of exports in BenchmarkCloudExportListItems.
+const benchmarkExportsCountEnv = "CLOUDEXPORT_BENCHMARK_EXPORTS"
+
+// BenchmarkCloudExportListItems measures setting items of the list for an account with 1k exports
+// and reports the size of the resulting state, to compare it with and without attributes selected.
+// Setting all attributes grows quadratically with the number of items. It takes about 5 seconds for 1k exports
+// and about 20 minutes for 10k exports, so run the latter once and with raised timeout:
+// CLOUDEXPORT_BENCHMARK_EXPORTS=10000 go test -run=^$ -bench=. -benchtime=1x -timeout=30m ./internal/provider.
+func BenchmarkCloudExportListItems(b *testing.B) {
+	if testing.Short() {
+		b.Skip("skipping benchmark of cloud export list items in short mode")
+	}
+	count := 1000
+	if v := os.Getenv(benchmarkExportsCountEnv); v != "" {
+		var err error
+		if count, err = strconv.Atoi(v); err != nil {
+			b.Fatalf("parse %v: %v", benchmarkExportsCountEnv, err)
+		}
+	}
+	exports := makeBenchmarkCloudExports(count)
+
+	benchmarks := []struct {
+		name       string
+		attributes []interface{}
+	}{
+		{
+			name:       "all attributes",
+			attributes: nil,
+		},
+		{
+			name:       "id, name and current_status",
+			attributes: []interface{}{"id", "name", "current_status"},
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			attributes := schema.NewSet(schema.HashString, bm.attributes)
+			var stateSize int
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				d := dataSourceCloudExportList().Data(nil)
+				d.SetId("benchmark")
+				items := cloudExportsToItems(exports, attributes, "https://portal.kentik.com")
+				if err := setCloudExportListItems(d, items, attributes.Len() > 0); err != nil {
+					b.Fatal(err)
+				}
+				state, err := json.Marshal(d.State().Attributes)
+				if err != nil {
+					b.Fatal(err)
+				}
+				stateSize = len(state)
+			}
+			b.ReportMetric(float64(stateSize), "state-bytes")
+		})
+	}
+}
+
+func makeBenchmarkCloudExports(n int) []models.CloudExport {
+	exports := make([]models.CloudExport, n)
+	for i := range exports {
+		id := strconv.Itoa(i + 1)
+		exports[i] = models.CloudExport{
+			ID:            id,
+			Type:          models.CloudExportTypeKentikManaged,
+			Enabled:       pointer.ToBool(true),
+			Name:          fmt.Sprintf("benchmark_aws_export_%v", id),
+			Description:   "benchmark aws cloud export",
+			PlanID:        "11467",
+			CloudProvider: models.CloudProviderAWS,
+			Properties: &models.AWSProperties{
+				Bucket:          fmt.Sprintf("benchmark-aws-bucket-%v", id),
+				IAMRoleARN:      "arn:aws:iam::003740049406:role/trafficTerraformIngestRole",
+				Region:          "us-east-2",
+				DeleteAfterRead: pointer.ToBool(false),
+				MultipleBuckets: pointer.ToBool(false),
+			},
+			BGP: &models.BGPProperties{
+				ApplyBGP:       pointer.ToBool(true),
+				UseBGPDeviceID: "1234",
+				DeviceBGPType:  "device",
+			},
+			CurrentStatus: &models.CloudExportStatus{
+				Status:               "OK",
+				ErrorMessage:         "No errors",
+				FlowFound:            pointer.ToBool(true),
+				APIAccess:            pointer.ToBool(true),
+				StorageAccountAccess: pointer.ToBool(true),
+			},
+		}
+	}
+	return exports
+}
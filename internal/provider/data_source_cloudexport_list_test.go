@@ -3,10 +3,13 @@ package provider_test
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/AlekSi/pointer"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	cloudexportpb "github.com/kentik/api-schema-public/gen/go/kentik/cloud_export/v202101beta1"
 	"github.com/kentik/community_sdk_golang/kentikapi/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,15 +34,454 @@ func TestDataSourceCloudExportList(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					// more properties are verified in TestDataSourceCloudExportItem* tests
 					resource.TestCheckResourceAttr(exportsDS, "items.0.name", "test_terraform_aws_export"),
-					resource.TestCheckResourceAttr(exportsDS, "items.1.name", "test_terraform_gce_export"),
-					resource.TestCheckResourceAttr(exportsDS, "items.2.name", "test_terraform_ibm_export"),
-					resource.TestCheckResourceAttr(exportsDS, "items.3.name", "test_terraform_azure_export"),
+					resource.TestCheckResourceAttr(exportsDS, "items.1.name", "test_terraform_azure_export"),
+					resource.TestCheckResourceAttr(exportsDS, "items.2.name", "test_terraform_gce_export"),
+					resource.TestCheckResourceAttr(exportsDS, "items.3.name", "test_terraform_ibm_export"),
+					resource.TestCheckResourceAttr(exportsDS, "items.3.portal_url", ""),
+					resource.TestCheckResourceAttr(exportsDS, "invalid_exports_count", "0"),
 				),
 			},
 		},
 	})
 }
 
+func TestDataSourceCloudExportListFilters(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	tests := []struct {
+		name          string
+		filters       string
+		expectedNames []string
+	}{
+		{
+			name:          "no filters",
+			filters:       "",
+			expectedNames: []string{"aws", "azure", "gce", "ibm"},
+		}, {
+			name:          "cloud provider",
+			filters:       `cloud_provider = "gce"`,
+			expectedNames: []string{"gce"},
+		}, {
+			name:          "type",
+			filters:       `type = "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"`,
+			expectedNames: []string{"aws", "azure", "ibm"},
+		}, {
+			name:          "enabled",
+			filters:       `enabled = true`,
+			expectedNames: []string{"aws", "azure", "gce"},
+		}, {
+			name:          "disabled",
+			filters:       `enabled = false`,
+			expectedNames: []string{"ibm"},
+		}, {
+			name:          "plan ID",
+			filters:       `plan_id = "21600"`,
+			expectedNames: []string{"gce"},
+		}, {
+			name:          "name regex",
+			filters:       `name_regex = "_(aws|azure)_"`,
+			expectedNames: []string{"aws", "azure"},
+		}, {
+			name:          "AWS bucket",
+			filters:       `aws_bucket = "terraform-aws-bucket"`,
+			expectedNames: []string{"aws"},
+		}, {
+			name:          "AWS bucket is matched exactly",
+			filters:       `aws_bucket = "terraform-aws"`,
+			expectedNames: nil,
+		}, {
+			name:          "AWS region",
+			filters:       `aws_region = "us-east-2"`,
+			expectedNames: []string{"aws"},
+		}, {
+			name:          "Azure subscription",
+			filters:       `azure_subscription_id = "784bd5ec-122b-41b7-9719-22f23d5b49c8"`,
+			expectedNames: []string{"azure"},
+		}, {
+			name:          "GCE project",
+			filters:       `gce_project = "project gce"`,
+			expectedNames: []string{"gce"},
+		}, {
+			name: "multiple filters",
+			filters: `type = "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"
+				enabled = true
+				plan_id = "11467"`,
+			expectedNames: []string{"aws", "azure"},
+		}, {
+			name:          "where expression",
+			filters:       `where = "export.enabled && export.type == 'CLOUD_EXPORT_TYPE_KENTIK_MANAGED'"`,
+			expectedNames: []string{"aws", "azure"},
+		}, {
+			name:          "where expression with nested attributes",
+			filters:       `where = "export.enabled && export.aws[0].region.startsWith('us-')"`,
+			expectedNames: []string{"aws"},
+		}, {
+			name:          "where expression with status",
+			filters:       `where = "export.current_status[0].status != 'OK'"`,
+			expectedNames: []string{"gce"},
+		}, {
+			name:          "where expression with absent nested object",
+			filters:       `where = "!has(export.bgp) && export.name.matches('_(gce|azure)_')"`,
+			expectedNames: []string{"azure", "gce"},
+		}, {
+			name:          "where expression with portal URL",
+			filters:       `where = "export.portal_url == '' && export.cloud_provider == 'ibm'"`,
+			expectedNames: []string{"ibm"},
+		}, {
+			name: "where expression with filters",
+			filters: `where = "export.plan_id in ['11467', '21600']"
+				enabled = false`,
+			expectedNames: []string{"ibm"},
+		}, {
+			name: "no matches",
+			filters: `cloud_provider = "aws"
+				aws_region = "eu-central-1"`,
+			expectedNames: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			checks := []resource.TestCheckFunc{
+				resource.TestCheckResourceAttr(exportsDS, "items.#", fmt.Sprint(len(tt.expectedNames))),
+			}
+			for i, name := range tt.expectedNames {
+				checks = append(checks, resource.TestCheckResourceAttr(
+					exportsDS, fmt.Sprintf("items.%d.name", i), fmt.Sprintf("test_terraform_%v_export", name),
+				))
+			}
+
+			resource.UnitTest(t, resource.TestCase{
+				ProviderFactories: providerFactories(),
+				Steps: []resource.TestStep{
+					{
+						Config: makeTestCloudExportDataSourceListWithFilters(server.URL(), tt.filters),
+						Check:  resource.ComposeTestCheckFunc(checks...),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestDataSourceCloudExportListSorting(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	tests := []struct {
+		name        string
+		sortBy      string
+		expectedIDs []string
+	}{
+		{
+			name:        "default",
+			sortBy:      "",
+			expectedIDs: []string{"1", "4", "2", "3"},
+		}, {
+			name:        "name",
+			sortBy:      `sort_by = "name"`,
+			expectedIDs: []string{"1", "4", "2", "3"},
+		}, {
+			name:        "id",
+			sortBy:      `sort_by = "id"`,
+			expectedIDs: []string{"1", "2", "3", "4"},
+		}, {
+			name:        "cloud_provider",
+			sortBy:      `sort_by = "cloud_provider"`,
+			expectedIDs: []string{"1", "4", "2", "3"},
+		}, {
+			name:        "plan_id",
+			sortBy:      `sort_by = "plan_id"`,
+			expectedIDs: []string{"1", "4", "3", "2"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var checks []resource.TestCheckFunc
+			for i, id := range tt.expectedIDs {
+				checks = append(checks, resource.TestCheckResourceAttr(exportsDS, fmt.Sprintf("items.%d.id", i), id))
+			}
+
+			resource.UnitTest(t, resource.TestCase{
+				ProviderFactories: providerFactories(),
+				Steps: []resource.TestStep{
+					{
+						Config: makeTestCloudExportDataSourceListWithFilters(server.URL(), tt.sortBy),
+						Check:  resource.ComposeTestCheckFunc(checks...),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestDataSourceCloudExportListID(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	var listID string
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceList(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(exportsDS, "id", regexp.MustCompile(`^[0-9a-f]{64}$`)),
+					resource.TestCheckResourceAttrWith(exportsDS, "id", func(value string) error {
+						listID = value
+						return nil
+					}),
+				),
+			},
+			{
+				// server order does not matter
+				PreConfig: func() {
+					server.withData(func(data []*cloudexportpb.CloudExport) {
+						data[0], data[3] = data[3], data[0]
+					})
+				},
+				Config: makeTestCloudExportDataSourceList(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(exportsDS, "id", &listID),
+				),
+			},
+			{
+				PreConfig: func() {
+					server.withData(func(data []*cloudexportpb.CloudExport) {
+						data[server.findByName("test_terraform_ibm_export")].Enabled = true
+					})
+				},
+				Config: makeTestCloudExportDataSourceList(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrWith(exportsDS, "id", func(value string) error {
+						if value == listID {
+							return fmt.Errorf("ID %q has not changed after the list content change", value)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportListIndexes(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceList(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(exportsDS, "ids_by_name.%", "4"),
+					resource.TestCheckResourceAttr(exportsDS, "ids_by_name.test_terraform_aws_export", "1"),
+					resource.TestCheckResourceAttr(exportsDS, "ids_by_name.test_terraform_gce_export", "2"),
+					resource.TestCheckResourceAttr(exportsDS, "ids_by_name.test_terraform_ibm_export", "3"),
+					resource.TestCheckResourceAttr(exportsDS, "ids_by_name.test_terraform_azure_export", "4"),
+					resource.TestCheckResourceAttr(exportsDS, "names.#", "4"),
+					resource.TestCheckTypeSetElemAttr(exportsDS, "names.*", "test_terraform_aws_export"),
+					resource.TestCheckTypeSetElemAttr(exportsDS, "names.*", "test_terraform_gce_export"),
+					resource.TestCheckTypeSetElemAttr(exportsDS, "names.*", "test_terraform_ibm_export"),
+					resource.TestCheckTypeSetElemAttr(exportsDS, "names.*", "test_terraform_azure_export"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.aws.#", "1"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.aws.0", "1"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.gce.#", "1"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.gce.0", "2"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.ibm.#", "1"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.ibm.0", "3"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.azure.#", "1"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.azure.0", "4"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceListWithFilters(server.URL(), `type = "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(exportsDS, "ids_by_name.%", "3"),
+					resource.TestCheckNoResourceAttr(exportsDS, "ids_by_name.test_terraform_gce_export"),
+					resource.TestCheckResourceAttr(exportsDS, "names.#", "3"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.aws.#", "1"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.gce.#", "0"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.ibm.#", "1"),
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.azure.#", "1"),
+				),
+			},
+			{
+				// the attributes are consumed directly, without intermediate locals
+				Config: makeTestCloudExportDataSourceList(server.URL()) + `
+					output "gce_export_id" {
+						value = data.kentik-cloudexport_list.exports.ids_by_name["test_terraform_gce_export"]
+					}
+					output "first_azure_export_id" {
+						value = data.kentik-cloudexport_list.exports.by_cloud_provider[0].azure[0]
+					}
+					output "has_ibm_export" {
+						value = contains(data.kentik-cloudexport_list.exports.names, "test_terraform_ibm_export")
+					}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("gce_export_id", "2"),
+					resource.TestCheckOutput("first_azure_export_id", "4"),
+					resource.TestCheckOutput("has_ibm_export", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportListInvalidExports(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.invalidExportsCount = 2
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				// invalid exports are reported with a warning, which does not fail the read
+				Config: makeTestCloudExportDataSourceListWithFilters(server.URL(), `cloud_provider = "aws"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(exportsDS, "invalid_exports_count", "2"),
+					resource.TestCheckResourceAttr(exportsDS, "items.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportListInvalidExportsWarning(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.invalidExportsCount = 2
+	server.Start()
+	defer server.Stop()
+
+	state, diags := readDataSource(t, server.URL(), "kentik-cloudexport_list", map[string]interface{}{
+		"cloud_provider": "aws",
+	})
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "Invalid cloud exports found", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "Kentik API reported 2 invalid cloud export(s)")
+	assert.Equal(t, "2", state.Attributes["invalid_exports_count"])
+	assert.Equal(t, "1", state.Attributes["items.#"])
+}
+
+func TestDataSourceCloudExportListAttributes(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourceListWithFilters(
+					server.URL(), `attributes = ["id", "name", "current_status"]`,
+				) + `
+					locals {
+						items = jsondecode(data.kentik-cloudexport_list.exports.items_json)
+					}
+					output "items_count" {
+						value = length(local.items)
+					}
+					output "first_item_attributes" {
+						value = join(",", sort(keys(local.items[0])))
+					}
+					output "first_item_name" {
+						value = local.items[0].name
+					}
+					output "third_item_status" {
+						value = local.items[2].current_status[0].status
+					}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(exportsDS, "items.#", "0"),
+					resource.TestCheckOutput("items_count", "4"),
+					resource.TestCheckOutput("first_item_attributes", "current_status,id,name"),
+					resource.TestCheckOutput("first_item_name", "test_terraform_aws_export"),
+					resource.TestCheckOutput("third_item_status", "NOK"),
+					// map-shaped attributes are not affected
+					resource.TestCheckResourceAttr(exportsDS, "by_cloud_provider.0.aws.0", "1"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourceList(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(exportsDS, "items.#", "4"),
+					resource.TestCheckResourceAttr(exportsDS, "items.0.cloud_provider", "aws"),
+					resource.TestCheckResourceAttr(exportsDS, "items_json", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportListInvalidFilters(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      makeTestCloudExportDataSourceListWithFilters(server.URL(), `name_regex = "test_("`),
+				ExpectError: regexp.MustCompile(`"name_regex": error parsing regexp`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceListWithFilters(server.URL(), `cloud_provider = "oracle"`),
+				ExpectError: regexp.MustCompile(`expected cloud_provider to be one of`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceListWithFilters(server.URL(), `where = "export.enabled &&"`),
+				ExpectError: regexp.MustCompile(`<input>:1:18: Syntax error`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceListWithFilters(server.URL(), `where = "region == 'us-east-2'"`),
+				ExpectError: regexp.MustCompile(`<input>:1:1: undeclared reference to 'region'`),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceListWithFilters(server.URL(), `where = "size(export.name)"`),
+				ExpectError: regexp.MustCompile(`the expression must evaluate to bool, got int`),
+			},
+			{
+				Config: makeTestCloudExportDataSourceListWithFilters(server.URL(), `where = "export.enabeld"`),
+				ExpectError: regexp.MustCompile(
+					`could not be evaluated for 4 cloud export\(s\), first error: .* no such key: enabeld`,
+				),
+			},
+			{
+				Config:      makeTestCloudExportDataSourceListWithFilters(server.URL(), `attributes = ["id", "region"]`),
+				ExpectError: regexp.MustCompile(`expected attributes to be one of \[.*\], got region`),
+			},
+		},
+	})
+}
+
 func makeTestCloudExportDataSourceList(apiURL string) string {
 	return fmt.Sprintf(`
 		provider "kentik-cloudexport" {
@@ -54,6 +496,22 @@ func makeTestCloudExportDataSourceList(apiURL string) string {
 	)
 }
 
+func makeTestCloudExportDataSourceListWithFilters(apiURL string, filters string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+		  
+		data "kentik-cloudexport_list" "exports" {
+			%v
+		}
+	`,
+		apiURL, filters,
+	)
+}
+
 // TestAccDataSourceCloudExportList is an acceptance test.
 // Checks if kentik-cloudexport_list data source method returns a list of resources.
 func TestAccDataSourceCloudExportList(t *testing.T) {
@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+)
+
+func dataSourceCloudExportPlans() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source listing Kentik plans, which cloud exports are associated with (plan_id attribute)",
+		ReadContext: dataSourceCloudExportPlansRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Only list plans with the name",
+				ConflictsWith: []string{"name_regex"},
+			},
+			"name_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only list plans with names matching the regular expression (Go RE2 syntax)",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+				ConflictsWith:    []string{"name"},
+			},
+			"active": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only list active (true) or inactive (false) plans",
+			},
+			"plans": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The plans, sorted by ID",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the plan, to be used as plan_id of cloud exports",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the plan",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Description of the plan",
+						},
+						"active": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the plan is active",
+						},
+						"max_devices": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Maximum number of devices in the plan",
+						},
+						"max_fps": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Maximum number of flows per second in the plan",
+						},
+						"bgp_enabled": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether BGP is enabled in the plan",
+						},
+						"device_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of devices currently assigned to the plan",
+						},
+						"available_devices": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of devices that can still be assigned to the plan (max_devices - device_count)",
+						},
+						"device_ids": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "IDs of devices currently assigned to the plan",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"ids_by_name": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Map of plan name to plan ID, e.g. to look up plan_id by plan name",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// planFilter selects the plans to list. Zero value matches all plans.
+type planFilter struct {
+	name      string
+	nameRegex *regexp.Regexp
+	active    *bool
+}
+
+func getPlanFilter(d *schema.ResourceData) (*planFilter, error) {
+	f := &planFilter{
+		name:   d.Get("name").(string),
+		active: getOptionalBool(d, "active"),
+	}
+
+	if nameRegex, ok := d.GetOk("name_regex"); ok {
+		r, err := regexp.Compile(nameRegex.(string))
+		if err != nil {
+			return nil, fmt.Errorf("compile name_regex: %v", err)
+		}
+		f.nameRegex = r
+	}
+	return f, nil
+}
+
+func (f *planFilter) matches(p *models.Plan) bool {
+	switch {
+	case f.name != "" && p.Name != f.name:
+		return false
+	case f.nameRegex != nil && !f.nameRegex.MatchString(p.Name):
+		return false
+	case f.active != nil && p.Active != *f.active:
+		return false
+	}
+	return true
+}
+
+func dataSourceCloudExportPlansRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	filter, err := getPlanFilter(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Debug(ctx, "Get all plans Kentik API request")
+	plans, err := m.(*apiClient).Plans.GetAll(ctx)
+	tflog.Debug(ctx, "Get all plans Kentik API response", map[string]interface{}{"response": plans})
+	if err != nil {
+		return detailedDiagError("Failed to read plans", err)
+	}
+
+	sort.SliceStable(plans, func(i, j int) bool {
+		return lessID(plans[i].ID, plans[j].ID)
+	})
+
+	items := []interface{}{}
+	idsByName := map[string]interface{}{}
+	for i := range plans {
+		if filter.matches(&plans[i]) {
+			items = append(items, planToMap(&plans[i]))
+			idsByName[plans[i].Name] = plans[i].ID
+		}
+	}
+
+	values := map[string]interface{}{
+		"plans":       items,
+		"ids_by_name": idsByName,
+	}
+	for k, v := range values {
+		if err = d.Set(k, v); err != nil {
+			return diag.FromErr(fmt.Errorf("set %v: %v", k, err))
+		}
+	}
+
+	if err = setContentHashID(d, items); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func planToMap(p *models.Plan) map[string]interface{} {
+	deviceIDs := make([]interface{}, 0, len(p.Devices))
+	for _, device := range p.Devices {
+		deviceIDs = append(deviceIDs, device.ID)
+	}
+	return map[string]interface{}{
+		"id":                p.ID,
+		"name":              p.Name,
+		"description":       p.Description,
+		"active":            p.Active,
+		"max_devices":       p.MaxDevices,
+		"max_fps":           p.MaxFPS,
+		"bgp_enabled":       p.BGPEnabled,
+		"device_count":      len(p.Devices),
+		"available_devices": p.MaxDevices - len(p.Devices),
+		"device_ids":        deviceIDs,
+	}
+}
@@ -0,0 +1,113 @@
+package provider_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const plansDS = "data.kentik-cloudexport_plans.plans"
+
+func TestDataSourceCloudExportPlans(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestCloudExportDataSourcePlans(server.URL(), ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(plansDS, "plans.#", "3"),
+					resource.TestCheckResourceAttr(plansDS, "plans.0.id", "9948"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.id", "11467"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.name", "Free Flowpak Plan"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.description", "Free Flowpak Plan description"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.active", "true"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.max_devices", "5"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.max_fps", "1000"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.bgp_enabled", "true"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.device_count", "4"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.available_devices", "1"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.device_ids.#", "4"),
+					resource.TestCheckResourceAttr(plansDS, "plans.1.device_ids.0", "1234"),
+					resource.TestCheckResourceAttr(plansDS, "plans.2.id", "21600"),
+					resource.TestCheckResourceAttr(plansDS, "ids_by_name.%", "3"),
+					resource.TestCheckResourceAttr(plansDS, "ids_by_name.Cloud Plan", "21600"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourcePlans(server.URL(), `name = "Free Flowpak Plan"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(plansDS, "plans.#", "1"),
+					resource.TestCheckResourceAttr(plansDS, "plans.0.id", "11467"),
+					resource.TestCheckResourceAttr(plansDS, "ids_by_name.Free Flowpak Plan", "11467"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourcePlans(server.URL(), `
+					name_regex = "Plan$"
+					active = false
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(plansDS, "plans.#", "1"),
+					resource.TestCheckResourceAttr(plansDS, "plans.0.name", "Legacy Plan"),
+					resource.TestCheckResourceAttr(plansDS, "plans.0.active", "false"),
+				),
+			},
+			{
+				Config: makeTestCloudExportDataSourcePlans(server.URL(), `name = "Nonexistent Plan"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(plansDS, "plans.#", "0"),
+					resource.TestCheckResourceAttr(plansDS, "ids_by_name.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceCloudExportPlansErrors(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      makeTestCloudExportDataSourcePlans(server.URL(), `name_regex = "["`),
+				ExpectError: regexp.MustCompile(`"name_regex":\s+error parsing regexp`),
+			},
+			{
+				Config: makeTestCloudExportDataSourcePlans(server.URL(), `
+					name = "Cloud Plan"
+					name_regex = "Plan$"
+				`),
+				ExpectError: regexp.MustCompile(`"name": conflicts with name_regex`),
+			},
+		},
+	})
+}
+
+func makeTestCloudExportDataSourcePlans(apiURL string, arguments string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+
+		data "kentik-cloudexport_plans" "plans" {
+			%v
+		}
+	`,
+		apiURL, arguments,
+	)
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/kentik/community_sdk_golang/kentikapi"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
+)
+
+// getDevices fetches all devices. Kentik API does not allow to filter devices.
+func getDevices(ctx context.Context, client *kentikapi.Client) ([]models.Device, error) {
+	tflog.Debug(ctx, "Get all devices Kentik API request")
+	devices, err := client.Devices.GetAll(ctx)
+	tflog.Debug(ctx, "Get all devices Kentik API response", map[string]interface{}{"response": devices})
+	if err != nil {
+		return nil, fmt.Errorf("get devices: %v", err)
+	}
+	return devices, nil
+}
+
+// findDevice looks up a device by ID or, if the ID is empty, by name.
+func findDevice(ctx context.Context, client *kentikapi.Client, id string, name string) (*models.Device, error) {
+	devices, err := getDevices(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range devices {
+		if (id != "" && devices[i].ID == id) || (id == "" && devices[i].DeviceName == name) {
+			return &devices[i], nil
+		}
+	}
+
+	if id != "" {
+		return nil, fmt.Errorf("device with ID %q does not exist", id)
+	}
+	return nil, fmt.Errorf("device with name %q does not exist", name)
+}
+
+// checkBGPDevice verifies that the device can provide BGP data to a cloud export,
+// i.e. it is a router with its own BGP session.
+func checkBGPDevice(d *models.Device) error {
+	if d.DeviceType != models.DeviceTypeRouter {
+		return fmt.Errorf("device %q (ID %v) is of type %q, want %q",
+			d.DeviceName, d.ID, d.DeviceType, models.DeviceTypeRouter)
+	}
+	if d.DeviceBGPType == nil || *d.DeviceBGPType != models.DeviceBGPTypeDevice {
+		return fmt.Errorf("device %q (ID %v) does not have BGP enabled", d.DeviceName, d.ID)
+	}
+	return nil
+}
+
+// isBGPConfigured reports whether the device is configured to provide BGP data,
+// either with its own BGP session or by sharing the session of other device.
+func isBGPConfigured(d *models.Device) bool {
+	return d.DeviceBGPType != nil && *d.DeviceBGPType != models.DeviceBGPTypeNone
+}
@@ -1,6 +1,12 @@
 package provider
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
 
 func getObjectFromNestedResourceData(data interface{}) (map[string]interface{}, error) {
 	dataSlice, ok := data.([]interface{})
@@ -26,3 +32,24 @@ func getObjectFromNestedResourceData(data interface{}) (map[string]interface{},
 
 	return m, nil
 }
+
+// getOptionalBool returns the value of the bool attribute or nil if it is not set in configuration.
+// d.GetOk cannot be used, as it does not distinguish false from unset attribute.
+func getOptionalBool(d *schema.ResourceData, key string) *bool {
+	if v := d.GetRawConfig().GetAttr(key); v.IsKnown() && !v.IsNull() {
+		b := v.True()
+		return &b
+	}
+	return nil
+}
+
+// setContentHashID sets SHA-256 checksum of JSON representation of the content as ID of the data source,
+// so that the ID only changes when the content changes.
+func setContentHashID(d *schema.ResourceData, content interface{}) error {
+	b, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("marshal content for ID: %v", err)
+	}
+	d.SetId(fmt.Sprintf("%x", sha256.Sum256(b)))
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -22,6 +24,14 @@ const (
 	maxDelayKey    = "max_delay"
 	logPayloadsKey = "log_payloads"
 
+	regionUS     = "US"
+	regionEU     = "EU"
+	regionCustom = "custom"
+
+	// cloudExportPortalPath is the path of Settings » Public Clouds page of Kentik v4 portal,
+	// which lists the cloud exports. The page of an export is available under the path followed by its ID.
+	cloudExportPortalPath = "/v4/setup/clouds"
+
 	defaultMaxAttempts = 100
 	defaultMinDelay    = "1s"
 	defaultMaxDelay    = "5m"
@@ -35,8 +45,17 @@ func New() *schema.Provider {
 			"kentik-cloudexport_item": resourceCloudExport(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"kentik-cloudexport_list": dataSourceCloudExportList(),
-			"kentik-cloudexport_item": dataSourceCloudExportItem(),
+			"kentik-cloudexport_list":                  dataSourceCloudExportList(),
+			"kentik-cloudexport_item":                  dataSourceCloudExportItem(),
+			"kentik-cloudexport_health":                dataSourceCloudExportHealth(),
+			"kentik-cloudexport_assert":                dataSourceCloudExportAssert(),
+			"kentik-cloudexport_aws_iam_policies":      dataSourceCloudExportAWSIAMPolicies(),
+			"kentik-cloudexport_azure_requirements":    dataSourceCloudExportAzureRequirements(),
+			"kentik-cloudexport_gce_requirements":      dataSourceCloudExportGCERequirements(),
+			"kentik-cloudexport_aws_flow_log_settings": dataSourceCloudExportAWSFlowLogSettings(),
+			"kentik-cloudexport_plans":                 dataSourceCloudExportPlans(),
+			"kentik-cloudexport_devices":               dataSourceCloudExportDevices(),
+			"kentik-cloudexport_caller_identity":       dataSourceCloudExportCallerIdentity(),
 		},
 		ConfigureContextFunc: configure,
 	}
@@ -140,7 +159,18 @@ func configure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.D
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
-	return client, nil
+
+	apiURL := resolveAPIURL(cfg)
+	portalURL, err := makePortalURL(apiURL)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	return &apiClient{
+		Client:    client,
+		authEmail: cfg.AuthEmail,
+		apiURL:    apiURL,
+		portalURL: portalURL,
+	}, nil
 }
 
 func getRetryConfig(ctx context.Context, d *schema.ResourceData) (kentikapi.RetryConfig, error) {
@@ -190,6 +220,48 @@ func getAPIURL(d *schema.ResourceData) string {
 	return ""
 }
 
+// resolveAPIURL returns the API URL used by the client, i.e. the configured one or the default one.
+func resolveAPIURL(cfg kentikapi.Config) string {
+	cfg.FillDefaults()
+	return cfg.APIURL
+}
+
+// makePortalURL derives Kentik portal URL from API URL, e.g. https://api.kentik.eu -> https://portal.kentik.eu.
+// The portal URL of custom API URLs without "api." host prefix is not known, so empty string is returned for them.
+func makePortalURL(apiURL string) (string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", fmt.Errorf("parse API URL: %v", err)
+	}
+	if !strings.HasPrefix(u.Host, "api.") {
+		return "", nil
+	}
+	return fmt.Sprintf("%v://portal.%v", u.Scheme, strings.TrimPrefix(u.Host, "api.")), nil
+}
+
+// getAPIRegion returns Kentik region of the API URL: US, EU or custom for other API servers.
+func getAPIRegion(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return regionCustom
+	}
+	switch u.Host {
+	case strings.TrimPrefix(kentikapi.APIURLUS, "https://"):
+		return regionUS
+	case strings.TrimPrefix(kentikapi.APIURLEU, "https://"):
+		return regionEU
+	}
+	return regionCustom
+}
+
+// makeCloudExportPortalURL returns URL of the export page in Kentik portal, or empty string if the portal is not known.
+func makeCloudExportPortalURL(portalURL string, id string) string {
+	if portalURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%v%v/%v", portalURL, cloudExportPortalPath, url.PathEscape(id))
+}
+
 func stripSensitiveData(cfg kentikapi.Config) kentikapi.Config {
 	cfg.AuthToken = "stripped"
 	return cfg
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/kentik/community_sdk_golang/kentikapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakePortalURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		apiURL            string
+		expectedPortalURL string
+	}{
+		{
+			name:              "default",
+			apiURL:            "",
+			expectedPortalURL: "https://portal.kentik.com",
+		}, {
+			name:              "US",
+			apiURL:            kentikapi.APIURLUS,
+			expectedPortalURL: "https://portal.kentik.com",
+		}, {
+			name:              "EU",
+			apiURL:            kentikapi.APIURLEU,
+			expectedPortalURL: "https://portal.kentik.eu",
+		}, {
+			name:              "custom with api prefix",
+			apiURL:            "https://api.kentik.example.com/api/v5",
+			expectedPortalURL: "https://portal.kentik.example.com",
+		}, {
+			name:              "custom without api prefix",
+			apiURL:            "http://127.0.0.1:8080",
+			expectedPortalURL: "",
+		}, {
+			name:              "custom with api in path only",
+			apiURL:            "https://kentik.example.com/api",
+			expectedPortalURL: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			portalURL, err := makePortalURL(resolveAPIURL(kentikapi.Config{APIURL: tt.apiURL}))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPortalURL, portalURL)
+		})
+	}
+}
+
+func TestMakeCloudExportPortalURL(t *testing.T) {
+	t.Parallel()
+	assert.Equal(
+		t, "https://portal.kentik.eu/v4/setup/clouds/42", makeCloudExportPortalURL("https://portal.kentik.eu", "42"),
+	)
+	assert.Equal(t, "", makeCloudExportPortalURL("", "42"))
+}
+
+func TestGetAPIRegion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		apiURL         string
+		expectedRegion string
+	}{
+		{apiURL: kentikapi.APIURLUS, expectedRegion: "US"},
+		{apiURL: kentikapi.APIURLUS + "/", expectedRegion: "US"},
+		{apiURL: kentikapi.APIURLEU, expectedRegion: "EU"},
+		{apiURL: "https://api.kentik.example.com", expectedRegion: "custom"},
+		{apiURL: "http://127.0.0.1:8080", expectedRegion: "custom"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.apiURL, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expectedRegion, getAPIRegion(tt.apiURL))
+		})
+	}
+}
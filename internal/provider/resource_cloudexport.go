@@ -2,11 +2,15 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"regexp"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/kentik/community_sdk_golang/kentikapi"
+	"github.com/kentik/community_sdk_golang/kentikapi/models"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -18,19 +22,209 @@ func resourceCloudExport() *schema.Resource {
 		ReadContext:   resourceCloudExportRead,
 		UpdateContext: resourceCloudExportUpdate,
 		DeleteContext: resourceCloudExportDelete,
-		Schema:        makeCloudExportSchema(create),
+		Importer: &schema.ResourceImporter{
+			StateContext: importCloudExport,
+		},
+		CustomizeDiff: customdiff.All(
+			validateCloudExportNameUnique,
+			resolveCloudExportBGPDevice,
+			planCloudExportIdentifiers,
+		),
+		Schema: makeCloudExportSchema(create),
 	}
 }
 
+// validateCloudExportNameUnique verifies on plan that the name of a new or renamed export is not used
+// by another export, which would make the server reject the export on apply.
+func validateCloudExportNameUnique(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("name") || !d.NewValueKnown("name") {
+		return nil
+	}
+	name := d.Get("name").(string)
+
+	tflog.Debug(ctx, "List cloud export Kentik API request")
+	listResp, err := m.(*apiClient).CloudExports.GetAll(ctx)
+	tflog.Debug(ctx, "List cloud export Kentik API response", map[string]interface{}{"response": listResp})
+	if err != nil {
+		return fmt.Errorf("failed to read cloud export list: %v", err)
+	}
+
+	for _, e := range listResp.CloudExports {
+		if e.Name == name && e.ID != d.Id() {
+			return fmt.Errorf(
+				"cloud export name %q is already used by cloud export with ID %v. "+
+					"Choose another name or import the existing export into Terraform state with: "+
+					"terraform import <resource address> %v",
+				name, e.ID, e.ID,
+			)
+		}
+	}
+	return nil
+}
+
+// resolveCloudExportBGPDevice verifies on plan that the BGP device referenced by the export
+// exists and is able to provide BGP data. The ID of the device is planned as bgp_device_id.
+// The device referenced by use_bgp_device_name is resolved on every plan, so that the plan shows a diff
+// when the device ID stored by the server no longer matches the name, e.g. when changed outside Terraform.
+func resolveCloudExportBGPDevice(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("bgp") && d.Get(bgpDeviceNamePath).(string) == "" {
+		return nil
+	}
+
+	bgp, err := getObjectFromNestedResourceData(d.Get("bgp"))
+	if err != nil {
+		return fmt.Errorf("bgp properties error: %s", err)
+	}
+	if bgp == nil {
+		return d.SetNew(bgpDeviceIDKey, "")
+	}
+	if !d.NewValueKnown(bgpDeviceIDPath) || !d.NewValueKnown(bgpDeviceNamePath) {
+		return d.SetNewComputed(bgpDeviceIDKey) // the device is not known until apply
+	}
+
+	id := bgp[useBGPDeviceIDKey].(string)
+	name := bgp[useBGPDeviceNameKey].(string)
+	if id == "" && name == "" {
+		return d.SetNew(bgpDeviceIDKey, "")
+	}
+
+	device, err := findDevice(ctx, m.(*apiClient).Client, id, name)
+	if err != nil {
+		return fmt.Errorf("invalid BGP device: %v", err)
+	}
+	if err = checkBGPDevice(device); err != nil {
+		return fmt.Errorf("invalid BGP device: %v", err)
+	}
+	return d.SetNew(bgpDeviceIDKey, device.ID)
+}
+
+// planCloudExportIdentifiers plans the identifiers of cloud resources derived from the export properties,
+// so that the plan does not show stale identifiers when the properties change.
+func planCloudExportIdentifiers(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() != "" && !d.HasChanges(awsKey, azureKey, gceKey) {
+		return nil
+	}
+
+	ids := makeCloudIdentifiers(nil)
+	for _, path := range cloudIdentifierSourcePaths {
+		if !d.NewValueKnown(path) {
+			// the identifiers are not known until apply
+			for k := range ids {
+				if err := d.SetNewComputed(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	for _, cloudProvider := range []string{awsKey, azureKey, gceKey} {
+		if _, ok := d.GetOk(cloudProvider); !ok {
+			continue
+		}
+		properties, err := resourceDataToCEProperties(cloudProvider, d)
+		if err != nil {
+			return err
+		}
+		ids = makeCloudIdentifiers(properties)
+		break
+	}
+
+	for k, v := range ids {
+		if err := d.SetNew(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloudIdentifierSourcePaths are the attributes the identifiers of cloud resources are derived from.
+var cloudIdentifierSourcePaths = []string{
+	awsKey,
+	awsKey + ".0.iam_role_arn",
+	awsKey + ".0." + awsBucketKey,
+	awsKey + ".0.region",
+	azureKey,
+	azureKey + ".0.subscription_id",
+	azureKey + ".0.resource_group",
+	azureKey + ".0.storage_account",
+	gceKey,
+	gceKey + ".0.project",
+	gceKey + ".0.subscription",
+}
+
+// generateCloudExportName returns a unique name beginning with name_prefix.
+func generateCloudExportName(d *schema.ResourceData) string {
+	return resource.PrefixedUniqueId(d.Get(namePrefixKey).(string))
+}
+
+// generatedNameSuffix matches the suffix appended to name_prefix by generateCloudExportName.
+var generatedNameSuffix = regexp.MustCompile(fmt.Sprintf("[[:xdigit:]]{%d}$", resource.UniqueIDSuffixLength))
+
+// importCloudExport imports the export with given ID. name_prefix is not stored by the server,
+// so it is derived from the name if the name looks generated from a prefix.
+func importCloudExport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	tflog.Debug(ctx, "Get cloud export Kentik API request", map[string]interface{}{"ID": d.Id()})
+	export, err := m.(*apiClient).CloudExports.Get(ctx, d.Id())
+	tflog.Debug(ctx, "Get cloud export Kentik API response", map[string]interface{}{"response": export})
+	if err != nil {
+		// the read following the import reports the error
+		return []*schema.ResourceData{d}, nil
+	}
+
+	if generatedNameSuffix.MatchString(export.Name) {
+		prefix := export.Name[:len(export.Name)-resource.UniqueIDSuffixLength]
+		if err = d.Set(namePrefixKey, prefix); err != nil {
+			return nil, err
+		}
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// resolveBGPDeviceName sets the ID of the BGP device referenced by use_bgp_device_name.
+// The ID resolved on plan is used, so that the applied ID matches the planned bgp_device_id.
+func resolveBGPDeviceName(
+	ctx context.Context, d *schema.ResourceData, m interface{}, export *models.CloudExport,
+) error {
+	name, ok := d.GetOk(bgpDeviceNamePath)
+	if !ok || export.BGP == nil {
+		return nil
+	}
+
+	if id, ok := d.GetOk(bgpDeviceIDKey); ok {
+		export.BGP.UseBGPDeviceID = id.(string)
+		return nil
+	}
+
+	// the name was not known on plan
+	device, err := findDevice(ctx, m.(*apiClient).Client, "", name.(string))
+	if err != nil {
+		return err
+	}
+	if err = checkBGPDevice(device); err != nil {
+		return err
+	}
+	export.BGP.UseBGPDeviceID = device.ID
+	return nil
+}
+
 func resourceCloudExportCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if _, ok := d.GetOk(namePrefixKey); ok {
+		if err := d.Set("name", generateCloudExportName(d)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	export, err := resourceDataToCloudExport(d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	if err = resolveBGPDeviceName(ctx, d, m, export); err != nil {
+		return detailedDiagError("Failed to resolve BGP device", err)
+	}
 
 	tflog.Debug(ctx, "Create cloud export Kentik API request", map[string]interface{}{"request": export})
-
-	export, err = m.(*kentikapi.Client).CloudExports.Create(ctx, export)
+	export, err = m.(*apiClient).CloudExports.Create(ctx, export)
 	tflog.Debug(ctx, "Create cloud export Kentik API response", map[string]interface{}{"response": export})
 	if err != nil {
 		return detailedDiagError("Failed to create cloud export", err)
@@ -49,7 +243,7 @@ func resourceCloudExportCreate(ctx context.Context, d *schema.ResourceData, m in
 
 func resourceCloudExportRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	tflog.Debug(ctx, "Get cloud export Kentik API request", map[string]interface{}{"ID": d.Get("id").(string)})
-	export, err := m.(*kentikapi.Client).CloudExports.Get(ctx, d.Get("id").(string))
+	export, err := m.(*apiClient).CloudExports.Get(ctx, d.Get("id").(string))
 
 	tflog.Debug(ctx, "Get cloud export Kentik API response", map[string]interface{}{"response": export})
 	if err != nil {
@@ -62,6 +256,12 @@ func resourceCloudExportRead(ctx context.Context, d *schema.ResourceData, m inte
 		return detailedDiagError("Failed to read cloud export", err)
 	}
 	mapExport := cloudExportToMap(export)
+	mapExport[portalURLKey] = makeCloudExportPortalURL(m.(*apiClient).portalURL, export.ID)
+	mapExport[bgpDeviceIDKey] = ""
+	if bgp, ok := mapExport["bgp"].([]interface{}); ok {
+		retainConfiguredBGPDevice(d, bgp[0].(map[string]interface{})) //nolint: forcetypeassert
+		mapExport[bgpDeviceIDKey] = export.BGP.UseBGPDeviceID
+	}
 	for k, v := range mapExport {
 		if err = d.Set(k, v); err != nil {
 			return diag.FromErr(err)
@@ -71,6 +271,16 @@ func resourceCloudExportRead(ctx context.Context, d *schema.ResourceData, m inte
 	return nil
 }
 
+// retainConfiguredBGPDevice leaves only one of use_bgp_device_id and use_bgp_device_name attributes,
+// the one provided by the user. BGP device name is not stored by the server, so the configured one is used.
+func retainConfiguredBGPDevice(d *schema.ResourceData, bgp map[string]interface{}) {
+	name := d.Get(bgpDeviceNamePath).(string)
+	bgp[useBGPDeviceNameKey] = name
+	if name != "" {
+		bgp[useBGPDeviceIDKey] = ""
+	}
+}
+
 func resourceCloudExportUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// check if any attribute has changed
 	if d.HasChange("") {
@@ -78,8 +288,11 @@ func resourceCloudExportUpdate(ctx context.Context, d *schema.ResourceData, m in
 		if err != nil {
 			return diag.FromErr(err)
 		}
+		if err = resolveBGPDeviceName(ctx, d, m, export); err != nil {
+			return detailedDiagError("Failed to resolve BGP device", err)
+		}
 		tflog.Debug(ctx, "Update cloud export Kentik API request", map[string]interface{}{"request": export})
-		resp, err := m.(*kentikapi.Client).CloudExports.Update(ctx, export)
+		resp, err := m.(*apiClient).CloudExports.Update(ctx, export)
 		tflog.Debug(ctx, "Update cloud export Kentik API response", map[string]interface{}{"response": resp})
 		if err != nil {
 			return detailedDiagError("Failed to update cloud export", err)
@@ -92,7 +305,7 @@ func resourceCloudExportUpdate(ctx context.Context, d *schema.ResourceData, m in
 
 func resourceCloudExportDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	tflog.Debug(ctx, "Delete cloud export Kentik API request", map[string]interface{}{"ID": d.Get("id").(string)})
-	err := m.(*kentikapi.Client).CloudExports.Delete(ctx, d.Get("id").(string))
+	err := m.(*apiClient).CloudExports.Delete(ctx, d.Get("id").(string))
 	if err != nil {
 		return detailedDiagError("Failed to delete cloud export", err)
 	}
@@ -2,10 +2,12 @@ package provider_test
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	cloudexportpb "github.com/kentik/api-schema-public/gen/go/kentik/cloud_export/v202101beta1"
 )
 
 // Note: we only check the user-provided values as we don't control the server-provided ones
@@ -31,6 +33,8 @@ func TestResourceCloudExportAWS(t *testing.T) {
 				Config: makeTestResourceCloudExportCreateAWS(server.URL()),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttrSet(ceAWSResource, "id"),
+					// portal of the test server is not known, as its host has no api. prefix
+					resource.TestCheckResourceAttr(ceAWSResource, "portal_url", ""),
 					resource.TestCheckResourceAttr(ceAWSResource, "type", "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"),
 					resource.TestCheckResourceAttr(ceAWSResource, "enabled", "true"),
 					resource.TestCheckResourceAttr(ceAWSResource, "name", "resource_test_terraform_aws_export"),
@@ -47,6 +51,11 @@ func TestResourceCloudExportAWS(t *testing.T) {
 					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.region", "eu-central-1"),
 					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.delete_after_read", "true"),
 					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.multiple_buckets", "true"),
+					resource.TestCheckResourceAttr(ceAWSResource, "aws_account_id", "003740049406"),
+					resource.TestCheckResourceAttr(ceAWSResource, "aws_role_name", "trafficTerraformIngestRole"),
+					resource.TestCheckResourceAttr(
+						ceAWSResource, "aws_bucket_arn", "arn:aws:s3:::resource-terraform-aws-bucket",
+					),
 				),
 			},
 			{
@@ -71,6 +80,10 @@ func TestResourceCloudExportAWS(t *testing.T) {
 					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.region", "eu-central-1-updated"),
 					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.delete_after_read", "false"),
 					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.multiple_buckets", "false"),
+					resource.TestCheckResourceAttr(ceAWSResource, "aws_role_name", "trafficTerraformIngestRole_updated"),
+					resource.TestCheckResourceAttr(
+						ceAWSResource, "aws_bucket_arn", "arn:aws:s3:::resource-terraform-aws-bucket-updated",
+					),
 				),
 			},
 			{
@@ -105,6 +118,9 @@ func TestResourceCloudExportGCE(t *testing.T) {
 					resource.TestCheckResourceAttr(ceGCEResource, "cloud_provider", "gce"),
 					resource.TestCheckResourceAttr(ceGCEResource, "gce.0.project", "gce project"),
 					resource.TestCheckResourceAttr(ceGCEResource, "gce.0.subscription", "gce subscription"),
+					resource.TestCheckResourceAttr(
+						ceGCEResource, "gce_subscription_path", "projects/gce project/subscriptions/gce subscription",
+					),
 				),
 			},
 			{
@@ -202,6 +218,12 @@ func TestResourceCloudExportAzure(t *testing.T) {
 					resource.TestCheckResourceAttr(ceAzureResource, "azure.0.storage_account", "kentikstorage"),
 					resource.TestCheckResourceAttr(ceAzureResource, "azure.0.subscription_id", "7777"),
 					resource.TestCheckResourceAttr(ceAzureResource, "azure.0.security_principal_enabled", "true"),
+					resource.TestCheckResourceAttr(
+						ceAzureResource,
+						"azure_storage_account_id",
+						"/subscriptions/7777/resourceGroups/traffic-generator"+
+							"/providers/Microsoft.Storage/storageAccounts/kentikstorage",
+					),
 				),
 			},
 			{
@@ -231,6 +253,289 @@ func TestResourceCloudExportAzure(t *testing.T) {
 	})
 }
 
+func TestResourceCloudExportBGPDevice(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestResourceCloudExportBGPDevice(server.URL(), `use_bgp_device_name= "bgp_router"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp.0.use_bgp_device_name", "bgp_router"),
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp.0.use_bgp_device_id", ""),
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp_device_id", "1234"),
+					testServerBGPDeviceID(server, "1234"),
+				),
+			},
+			{
+				// the device is changed outside Terraform, so the configured name is resolved again
+				PreConfig: func() {
+					server.withData(func(data []*cloudexportpb.CloudExport) {
+						data[server.findByName("resource_test_terraform_bgp_device_export")].Bgp.UseBgpDeviceId = "4444"
+					})
+				},
+				Config: makeTestResourceCloudExportBGPDevice(server.URL(), `use_bgp_device_name= "bgp_router"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp.0.use_bgp_device_name", "bgp_router"),
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp_device_id", "1234"),
+					testServerBGPDeviceID(server, "1234"),
+				),
+			},
+			{
+				Config: makeTestResourceCloudExportBGPDevice(server.URL(), `use_bgp_device_name= "bgp_router_2"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp.0.use_bgp_device_name", "bgp_router_2"),
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp.0.use_bgp_device_id", ""),
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp_device_id", "4444"),
+					testServerBGPDeviceID(server, "4444"),
+				),
+			},
+			{
+				Config: makeTestResourceCloudExportBGPDevice(server.URL(), `use_bgp_device_id= "1234"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp.0.use_bgp_device_name", ""),
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp.0.use_bgp_device_id", "1234"),
+					resource.TestCheckResourceAttr(ceIBMResource, "bgp_device_id", "1234"),
+				),
+			},
+			{
+				Config:      makeTestResourceCloudExportBGPDevice(server.URL(), `use_bgp_device_id= "9999"`),
+				ExpectError: regexp.MustCompile(`device with ID "9999" does not exist`),
+			},
+			{
+				Config:      makeTestResourceCloudExportBGPDevice(server.URL(), `use_bgp_device_name= "missing"`),
+				ExpectError: regexp.MustCompile(`device with name "missing" does not exist`),
+			},
+			{
+				Config:      makeTestResourceCloudExportBGPDevice(server.URL(), `use_bgp_device_id= "5555"`),
+				ExpectError: regexp.MustCompile(`device "non_bgp_router" \(ID 5555\) does not have BGP enabled`),
+			},
+			{
+				Config:      makeTestResourceCloudExportBGPDevice(server.URL(), `use_bgp_device_id= "6666"`),
+				ExpectError: regexp.MustCompile(`device "dns_probe" \(ID 6666\) is of type "host-nprobe-dns-www"`),
+			},
+			{
+				Config: makeTestResourceCloudExportBGPDevice(
+					server.URL(), `use_bgp_device_id= "1234"
+					use_bgp_device_name= "bgp_router"`,
+				),
+				ExpectError: regexp.MustCompile(`only one of`),
+			},
+			{
+				Config: makeTestResourceCloudExportDestroy(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceDoesntExists(ceIBMResource),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceCloudExportNameUnique(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestResourceCloudExportWithName(server.URL(), "test_terraform_gce_export"),
+				ExpectError: regexp.MustCompile(
+					`cloud export name "test_terraform_gce_export" is already used by cloud export with ID 2`,
+				),
+			},
+			{
+				Config: makeTestResourceCloudExportWithName(server.URL(), "resource_test_terraform_unique_export"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceIBMResource, "name", "resource_test_terraform_unique_export"),
+				),
+			},
+			{
+				Config:      makeTestResourceCloudExportWithName(server.URL(), "test_terraform_aws_export"),
+				ExpectError: regexp.MustCompile(`terraform import <resource address> 1`),
+			},
+			{
+				ResourceName:      ceIBMResource,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: makeTestResourceCloudExportDestroy(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceDoesntExists(ceIBMResource),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceCloudExportCloudIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	// the identifiers are used by another resource, so stale planned values would fail the apply
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestResourceCloudExportCloudIdentifiers(server.URL(), "trafficTerraformIngestRole"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceAWSResource, "aws_account_id", "003740049406"),
+					resource.TestCheckResourceAttr(ceAWSResource, "aws_role_name", "trafficTerraformIngestRole"),
+					resource.TestCheckResourceAttr(ceAWSResource, "azure_storage_account_id", ""),
+					resource.TestCheckResourceAttr(ceIBMResource, "description", "trafficTerraformIngestRole"),
+				),
+			},
+			{
+				Config: makeTestResourceCloudExportCloudIdentifiers(server.URL(), "trafficTerraformIngestRole_updated"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceAWSResource, "aws_role_name", "trafficTerraformIngestRole_updated"),
+					resource.TestCheckResourceAttr(ceIBMResource, "description", "trafficTerraformIngestRole_updated"),
+				),
+			},
+			{
+				Config: makeTestResourceCloudExportDestroy(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceDoesntExists(ceAWSResource),
+					testResourceDoesntExists(ceIBMResource),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceCloudExportImport(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestResourceCloudExportCreateIBM(server.URL()),
+			},
+			{
+				ResourceName:      ceIBMResource,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:  ceIBMResource,
+				ImportState:   true,
+				ImportStateId: "999",
+				ExpectError:   regexp.MustCompile(`Cannot import non-existent remote object`),
+			},
+			{
+				Config: makeTestResourceCloudExportDestroy(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceDoesntExists(ceIBMResource),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceCloudExportNamePrefix(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestResourceCloudExportWithNameAttribute(server.URL(), `name_prefix= "resource_test_prefix-"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceIBMResource, "name_prefix", "resource_test_prefix-"),
+					resource.TestMatchResourceAttr(ceIBMResource, "name", regexp.MustCompile(`^resource_test_prefix-\d+$`)),
+				),
+			},
+			{
+				ResourceName:      ceIBMResource,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config:      makeTestResourceCloudExportWithNameAttribute(server.URL(), ""),
+				ExpectError: regexp.MustCompile("one of `name,name_prefix` must be specified"),
+			},
+			{
+				Config: makeTestResourceCloudExportWithNameAttribute(
+					server.URL(), `name= "resource_test_name"
+					name_prefix= "resource_test_prefix-"`,
+				),
+				ExpectError: regexp.MustCompile("only one of `name,name_prefix` can be specified"),
+			},
+			{
+				Config: makeTestResourceCloudExportDestroy(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceDoesntExists(ceIBMResource),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceCloudExportAWSBucket(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t, makeInitialCloudExports())
+	server.Start()
+	defer server.Stop()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: makeTestResourceCloudExportAWSBucket(server.URL(), `bucket= "bucket-1"
+					multiple_buckets= true`,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.bucket", "bucket-1"),
+					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.multiple_buckets", "true"),
+					testServerAWSBucket(server, "bucket-1", true),
+				),
+			},
+			{
+				Config: makeTestResourceCloudExportAWSBucket(server.URL(), `bucket= "bucket-2"
+					multiple_buckets= false`,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.bucket", "bucket-2"),
+					resource.TestCheckResourceAttr(ceAWSResource, "aws.0.multiple_buckets", "false"),
+					testServerAWSBucket(server, "bucket-2", false),
+				),
+			},
+			{
+				Config:      makeTestResourceCloudExportAWSBucket(server.URL(), `multiple_buckets= false`),
+				ExpectError: regexp.MustCompile(`The argument "bucket" is required`),
+			},
+			{
+				Config: makeTestResourceCloudExportDestroy(server.URL()),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceDoesntExists(ceAWSResource),
+				),
+			},
+		},
+	})
+}
+
 func testResourceDoesntExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		// find the corresponding state object
@@ -243,6 +548,56 @@ func testResourceDoesntExists(name string) resource.TestCheckFunc {
 	}
 }
 
+// testServerBGPDeviceID verifies the BGP device ID stored by the server for the test_ibm export.
+func testServerBGPDeviceID(server *testAPIServer, deviceID string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[ceIBMResource]
+		if !ok {
+			return fmt.Errorf("resource %q not found", ceIBMResource)
+		}
+
+		var err error
+		server.withData(func(data []*cloudexportpb.CloudExport) {
+			i := server.findByID(rs.Primary.ID)
+			if i == cloudExportNotFound {
+				err = fmt.Errorf("cloud export with ID %q not found on server", rs.Primary.ID)
+				return
+			}
+			if got := data[i].GetBgp().GetUseBgpDeviceId(); got != deviceID {
+				err = fmt.Errorf("server stores BGP device ID %q, want %q", got, deviceID)
+			}
+		})
+		return err
+	}
+}
+
+// testServerAWSBucket verifies the bucket properties stored by the server for the test_aws export.
+func testServerAWSBucket(server *testAPIServer, bucket string, multipleBuckets bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[ceAWSResource]
+		if !ok {
+			return fmt.Errorf("resource %q not found", ceAWSResource)
+		}
+
+		var err error
+		server.withData(func(data []*cloudexportpb.CloudExport) {
+			i := server.findByID(rs.Primary.ID)
+			if i == cloudExportNotFound {
+				err = fmt.Errorf("cloud export with ID %q not found on server", rs.Primary.ID)
+				return
+			}
+			aws := data[i].GetAws()
+			if aws.GetBucket() != bucket || aws.GetMultipleBuckets() != multipleBuckets {
+				err = fmt.Errorf(
+					"server stores bucket %q with multiple_buckets %v, want bucket %q with multiple_buckets %v",
+					aws.GetBucket(), aws.GetMultipleBuckets(), bucket, multipleBuckets,
+				)
+			}
+		})
+		return err
+	}
+}
+
 func makeTestResourceCloudExportCreateAWS(apiURL string) string {
 	return fmt.Sprintf(`
 		provider "kentik-cloudexport" {
@@ -463,6 +818,126 @@ func makeTestResourceCloudExportUpdateAzure(apiURL string) string {
 	)
 }
 
+func makeTestResourceCloudExportBGPDevice(apiURL string, bgpDevice string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+		
+		resource "kentik-cloudexport_item" "test_ibm" {
+			name= "resource_test_terraform_bgp_device_export"
+			type= "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"
+			enabled=true
+			plan_id= "9948"
+			cloud_provider= "ibm"
+			bgp {
+				apply_bgp= true
+				%v
+				device_bgp_type= "other_device"
+			}
+			ibm {
+				bucket= "ibm-bucket"
+			}
+		  }
+		`,
+		apiURL, bgpDevice,
+	)
+}
+
+func makeTestResourceCloudExportWithName(apiURL string, name string) string {
+	return makeTestResourceCloudExportWithNameAttribute(apiURL, fmt.Sprintf(`name= "%v"`, name))
+}
+
+func makeTestResourceCloudExportWithNameAttribute(apiURL string, nameAttribute string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+		
+		resource "kentik-cloudexport_item" "test_ibm" {
+			%v
+			type= "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"
+			enabled=true
+			plan_id= "9948"
+			cloud_provider= "ibm"
+			ibm {
+				bucket= "ibm-bucket"
+			}
+		  }
+		`,
+		apiURL, nameAttribute,
+	)
+}
+
+func makeTestResourceCloudExportAWSBucket(apiURL string, bucketAttributes string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+		
+		resource "kentik-cloudexport_item" "test_aws" {
+			name= "resource_test_terraform_aws_bucket_export"
+			type= "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"
+			enabled=true
+			plan_id= "9948"
+			cloud_provider= "aws"
+			aws {
+				%v
+				iam_role_arn= "arn:aws:iam::003740049406:role/trafficTerraformIngestRole"
+				region= "eu-central-1"
+				delete_after_read= false
+			}
+		  }
+		`,
+		apiURL, bucketAttributes,
+	)
+}
+
+func makeTestResourceCloudExportCloudIdentifiers(apiURL string, roleName string) string {
+	return fmt.Sprintf(`
+		provider "kentik-cloudexport" {
+			apiurl = "%v"
+			email = "joe.doe@example.com"
+			token = "dummy-token"
+		}
+
+		resource "kentik-cloudexport_item" "test_aws" {
+			name= "resource_test_terraform_aws_export"
+			type= "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"
+			enabled=true
+			plan_id= "9948"
+			cloud_provider= "aws"
+			aws {
+				bucket= "resource-terraform-aws-bucket"
+				iam_role_arn= "arn:aws:iam::003740049406:role/%v"
+				region= "eu-central-1"
+				delete_after_read= true
+				multiple_buckets= false
+			}
+		}
+
+		resource "kentik-cloudexport_item" "test_ibm" {
+			name= "resource_test_terraform_ibm_export"
+			type= "CLOUD_EXPORT_TYPE_KENTIK_MANAGED"
+			enabled=true
+			description= kentik-cloudexport_item.test_aws.aws_role_name
+			plan_id= "9948"
+			cloud_provider= "ibm"
+			ibm {
+				bucket= "ibm-bucket"
+			}
+		}
+		`,
+		apiURL, roleName,
+	)
+}
+
 func makeTestResourceCloudExportDestroy(apiURL string) string {
 	return fmt.Sprintf(`
 		provider "kentik-cloudexport" {
@@ -494,7 +969,7 @@ func TestAccResourceCloudExportAWS(t *testing.T) {
 						resource.TestCheckResourceAttr(
 							ceAWSResource,
 							"bgp.0.use_bgp_device_id",
-							fmt.Sprintf("%s-bgp-id", getAccTestPrefix())),
+							getKentikBGPDeviceIDAccTests()),
 						resource.TestCheckResourceAttr(ceAWSResource, "bgp.0.device_bgp_type", "router"),
 						resource.TestCheckResourceAttr(ceAWSResource, "aws.0.bucket", fmt.Sprintf("%s-aws-bucket", getAccTestPrefix())),
 						resource.TestCheckResourceAttr(
@@ -523,7 +998,7 @@ func TestAccResourceCloudExportAWS(t *testing.T) {
 						resource.TestCheckResourceAttr(
 							ceAWSResource,
 							"bgp.0.use_bgp_device_id",
-							fmt.Sprintf("%s-bgp-id-update", getAccTestPrefix())),
+							getKentikBGPDeviceIDAccTests()),
 						resource.TestCheckResourceAttr(ceAWSResource, "bgp.0.device_bgp_type", "dns"),
 						resource.TestCheckResourceAttr(
 							ceAWSResource,
@@ -715,7 +1190,7 @@ func makeTestAccResourceCloudExportCreateAWS() string {
 			cloud_provider= "aws"
 			bgp {
 				apply_bgp= true
-				use_bgp_device_id= "%[1]s-bgp-id"
+				use_bgp_device_id= "%[3]s"
 				device_bgp_type= "router"
 			}
 			aws {
@@ -726,7 +1201,7 @@ func makeTestAccResourceCloudExportCreateAWS() string {
 				multiple_buckets= true
 			}
 		  }
-		`, getAccTestPrefix(), getKentikPlanIDAccTests())
+		`, getAccTestPrefix(), getKentikPlanIDAccTests(), getKentikBGPDeviceIDAccTests())
 }
 
 func makeTestAccResourceCloudExportUpdateAWS() string {
@@ -740,7 +1215,7 @@ func makeTestAccResourceCloudExportUpdateAWS() string {
 			cloud_provider= "aws"
 			bgp {
 				apply_bgp= false
-				use_bgp_device_id= "%[1]s-bgp-id-update"
+				use_bgp_device_id= "%[3]s"
 				device_bgp_type= "dns"
 			}
 			aws {
@@ -751,7 +1226,7 @@ func makeTestAccResourceCloudExportUpdateAWS() string {
 				multiple_buckets= false
 			}
 		  }
-		`, getAccTestPrefix(), getKentikPlanIDAccTests())
+		`, getAccTestPrefix(), getKentikPlanIDAccTests(), getKentikBGPDeviceIDAccTests())
 }
 
 func makeTestAccResourceCloudExportCreateGCE() string {
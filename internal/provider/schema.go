@@ -3,7 +3,7 @@ package provider
 import "github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 // schemaMode determines if we want a schema for:
-// - reading single item - we need to provide "id" of the item to read, everything else is provided by the server,
+// - reading single item - we need to provide a lookup key, e.g. "id" or "name", the rest is provided by the server,
 // - reading list of items - we don't need to provide a thing, everything is provided by the server,
 // - creating new item - we need to provide a bunch of obligatory attributes, the rest is provided by the server.
 type schemaMode int
@@ -27,3 +27,10 @@ func skipOnReadOneOf(mode schemaMode, providers []string) []string {
 	}
 	return providers
 }
+
+func skipOnReadMaxItems(mode schemaMode, maxItems int) int {
+	if mode == readSingle || mode == readList {
+		return 0
+	}
+	return maxItems
+}